@@ -0,0 +1,172 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-word-create/internal/cli"
+	"go-word-create/internal/config"
+	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/report"
+	"go-word-create/internal/word"
+)
+
+func main() {
+	// Load configuration from .env file
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Define command line flags
+	projectKey := flag.String("project", "", "Report on every epic of the project (default JIRA_PROJECT_KEY)")
+	boardName := flag.String("board", "", "Report on the epics of the issues in the board's sprints")
+	epicList := flag.String("epics", "", "Report on the given epics, comma separated keys, e.g. PROJ-1,PROJ-7")
+	typeList := flag.String("types", "Bug,Feature,Story,Task", "Child issue types counted, comma separated")
+	flags := cli.RegisterFlags(cfg)
+	flags.RegisterFilter("Report on the epics of the saved Jira filter's issues, by ID or name")
+	flags.RegisterJQL()
+	flags.RegisterColumns()
+	flag.Parse()
+
+	scope := jiraservice.EpicScope{ProjectKey: *projectKey, BoardName: *boardName, EpicKeys: cli.SplitList(*epicList)}
+	given := 0
+	for _, set := range []bool{scope.ProjectKey != "", scope.BoardName != "", flags.Filter != "", len(scope.EpicKeys) > 0} {
+		if set {
+			given++
+		}
+	}
+	if given > 1 {
+		fmt.Println("Error: at most one of -project, -board, -filter or -epics can be given")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if given == 0 {
+		scope.ProjectKey = cfg.ProjectKey
+	}
+
+	if err := flags.Apply(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Cancel Jira calls on Ctrl+C and, if requested, after the timeout
+	ctx, cancel := flags.Context()
+	defer cancel()
+
+	// Create Jira service
+	jiraService, err := flags.NewJiraService()
+	if err != nil {
+		log.Fatalf("Failed to create Jira service: %v", err)
+	}
+
+	scope.Filter, err = flags.FindFilter(ctx, jiraService)
+	if err != nil {
+		log.Fatalf("Failed to get filter: %v", err)
+	}
+	heading := "Epic progress"
+	if scope.Filter != nil {
+		heading += fmt.Sprintf(" in '%s'", scope.Filter.Name)
+	}
+
+	epics, err := jiraService.GetEpicProgressWithContext(ctx, scope, cli.SplitList(*typeList))
+	if err != nil {
+		log.Fatalf("Failed to get epics: %v", err)
+	}
+	if len(epics) == 0 {
+		log.Fatalf("No epics to report on")
+	}
+
+	summaries := make([]report.EpicSummary, len(epics))
+	for i, epic := range epics {
+		summaries[i] = report.SummarizeEpic(epic)
+	}
+
+	if flags.Debug {
+		// Print debug information
+		fmt.Println(heading)
+		fmt.Printf("%-12s|%-40s|%6s|%6s|%8s|%8s|%5s|%-10s|%-10s\n",
+			"Epic", "Name", "Issues", "Done", "Done SP", "Left SP", "%", "Started", "Activity")
+		for _, s := range summaries {
+			fmt.Printf("%-12s|%-40s|%6d|%6d|%8.1f|%8.1f|%4.0f%%|%-10s|%-10s\n",
+				s.Key, cli.Truncate(s.Name, 40), s.Issues, s.Done, s.DonePoints, s.RemainingPoints,
+				s.Complete()*100, report.FormatDate(s.Started), report.FormatDate(s.LastActivity))
+		}
+		for _, epic := range epics {
+			fmt.Printf("\n%s %s:\n", epic.Key, epic.Name)
+			for _, issue := range epic.Children {
+				fmt.Printf("%-8s|%-12s|%-80s|%.1f|%-12s\n",
+					issue.Type, issue.Key, cli.Truncate(issue.Summary, 80), issue.StoryPoints, issue.Status)
+			}
+		}
+		return
+	}
+
+	// Create Word document
+	doc := word.NewDocument()
+	doc.AddHeading(1, heading)
+	addSummaryTable(doc, summaries)
+	for i, epic := range epics {
+		addChildrenTable(doc, summaries[i].Heading(), epic.Children, flags.Columns)
+	}
+
+	// Save the document
+	if err := doc.SaveDocumentToFile(&flags.Output); err != nil {
+		log.Fatalf("Failed to save document: %v", err)
+	}
+
+	fmt.Printf("Created document '%s' with %d epics\n", flags.Output, len(epics))
+}
+
+// addSummaryTable adds a table with the progress of every epic
+func addSummaryTable(doc *word.Doc, summaries []report.EpicSummary) {
+	table := word.NewTable(&doc.WordDocument)
+	table.SetLeftAligned(1, 6)
+	table.AddHeaderRow([]string{"Epic", "Name", "Issues", "Done SP", "Remaining SP", "Complete", "Progress", "Started", "Last Activity"})
+	for _, s := range summaries {
+		table.AddDataRow([]string{
+			s.Key,
+			s.Name,
+			fmt.Sprintf("%d/%d", s.Done, s.Issues),
+			strconv.FormatFloat(s.DonePoints, 'f', 1, 64),
+			strconv.FormatFloat(s.RemainingPoints, 'f', 1, 64),
+			fmt.Sprintf("%.0f%%", s.Complete()*100),
+			report.ProgressBar(s.Complete()),
+			report.FormatDate(s.Started),
+			report.FormatDate(s.LastActivity),
+		})
+	}
+}
+
+// addChildrenTable adds a heading and a table of the epic's child issues
+func addChildrenTable(doc *word.Doc, headingText string, issues []jiraservice.Issue, columns report.Columns) {
+	doc.AddHeading(2, headingText)
+	if len(issues) == 0 {
+		doc.AddParagraph("No child issues")
+		return
+	}
+	table := word.NewTable(&doc.WordDocument)
+
+	// Add header row
+	headers := []string{"Type", "Key", "Summary", "Status", "Story Points"}
+	table.SetLeftAligned(append([]int{2, 3}, columns.LeftAligned(len(headers))...)...)
+	table.AddHeaderRow(append(headers, columns.Headers()...))
+
+	// Add issue rows
+	for _, issue := range issues {
+		data := []string{
+			issue.Type,
+			issue.Key,
+			issue.Summary,
+			issue.Status,
+			strconv.FormatFloat(issue.StoryPoints, 'f', 1, 64),
+		}
+		table.AddDataRow(append(data, columns.Values(issue)...))
+	}
+
+	total := []string{"", "", "Total", "", strconv.FormatFloat(report.StoryPoints(issues), 'f', 1, 64)}
+	table.AddDataRow(append(total, make([]string, len(columns))...))
+}
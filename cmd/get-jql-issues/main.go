@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-word-create/internal/cli"
+	"go-word-create/internal/config"
+	"go-word-create/internal/jql"
+	"go-word-create/internal/word"
+)
+
+func main() {
+	// Load configuration from .env file
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Define command line flags
+	query := flag.String("jql", "", "JQL query selecting the issues, e.g. 'project = PROJ AND fixVersion = 1.4.0 ORDER BY key'")
+	flags := cli.RegisterFlags(cfg)
+	flags.RegisterFilter("Saved Jira filter to run instead of -jql, by ID or name")
+	flags.RegisterColumns()
+	flag.Parse()
+
+	if err := flags.Apply(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Validate required flags
+	if (*query == "") == (flags.Filter == "") {
+		fmt.Println("Error: exactly one of -jql or -filter is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *query != "" {
+		if _, _, err := jql.Split(*query); err != nil {
+			fmt.Printf("Error: invalid -jql: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Cancel Jira calls on Ctrl+C and, if requested, after the timeout
+	ctx, cancel := flags.Context()
+	defer cancel()
+
+	// Create Jira service
+	jiraService, err := flags.NewJiraService()
+	if err != nil {
+		log.Fatalf("Failed to create Jira service: %v", err)
+	}
+
+	// A saved filter supplies the query and names the report
+	heading := fmt.Sprintf("Issues matching %s", *query)
+	filter, err := flags.FindFilter(ctx, jiraService)
+	if err != nil {
+		log.Fatalf("Failed to get filter: %v", err)
+	}
+	if filter != nil {
+		*query = filter.JQL
+		heading = fmt.Sprintf("Issues in filter '%s'", filter.Name)
+	}
+
+	// Get the matching issues, of any type
+	issues, err := jiraService.GetIssuesByJQLWithContext(ctx, *query, nil)
+	if err != nil {
+		log.Fatalf("Failed to get issues: %v", err)
+	}
+
+	if flags.Debug {
+		// Print debug information
+		fmt.Printf("Found %d issues: %s\n", len(issues), heading)
+		fmt.Println("\nIssues:")
+		for _, issue := range issues {
+			// Truncate strings that are too long
+			fmt.Printf("%-8s|%-12s|%-80s|%-40s|%.1f|%-12s\n",
+				issue.Type, issue.Key, cli.Truncate(issue.Summary, 80), cli.Truncate(issue.Epic, 40), issue.StoryPoints, issue.Status)
+		}
+		fmt.Printf("\nTotal issues: %d\n", len(issues))
+	} else {
+		// Create Word document
+		doc := word.NewDocument()
+		doc.AddHeading(1, heading)
+		table := word.NewTable(&doc.WordDocument)
+
+		// Add header row
+		headers := []string{"Type", "Key", "Summary", "Epic", "Story Points"}
+		table.SetLeftAligned(append([]int{2, 3}, flags.Columns.LeftAligned(len(headers))...)...)
+		table.AddHeaderRow(append(headers, flags.Columns.Headers()...))
+
+		// Add issue rows
+		for _, issue := range issues {
+			data := []string{
+				issue.Type,
+				issue.Key,
+				issue.Summary,
+				issue.Epic,
+				strconv.FormatFloat(issue.StoryPoints, 'f', 1, 64),
+			}
+			table.AddDataRow(append(data, flags.Columns.Values(issue)...))
+		}
+
+		// Save the document
+		err = doc.SaveDocumentToFile(&flags.Output)
+		if err != nil {
+			log.Fatalf("Failed to save document: %v", err)
+		}
+
+		fmt.Printf("Created document '%s' with %d issues\n", flags.Output, len(issues))
+	}
+}
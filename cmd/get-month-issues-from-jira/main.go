@@ -6,21 +6,14 @@ import (
 	"log"
 	"os"
 	"strconv"
-	"time"
 
+	"go-word-create/internal/cli"
 	"go-word-create/internal/config"
 	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/report"
 	"go-word-create/internal/word"
 )
 
-// truncate cuts a string if it's longer than maxLen and adds "..." at the end
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
-
 func main() {
 	// Load configuration from .env file
 	cfg, err := config.Load()
@@ -29,80 +22,101 @@ func main() {
 	}
 
 	// Define command line flags
-	month := flag.String("month", "", "Month in format YYYY.MM (required)")
-	outputFile := flag.String("output", cfg.OutputFile, "Output file name")
-	debugMode := flag.Bool("debug", false, "Debug mode: print data without generating Word document")
+	month := flag.String("month", "", "Month in format YYYY.MM")
+	week := flag.String("week", "", "ISO week in format YYYY-Www, e.g. 2025-W42")
+	quarter := flag.String("quarter", "", "Quarter in format YYYYQn, e.g. 2025Q4")
+	from := flag.String("from", "", "First day of a custom range in format YYYY-MM-DD")
+	to := flag.String("to", "", "Last day of a custom range in format YYYY-MM-DD (default: today)")
+	flags := cli.RegisterFlags(cfg)
+	flags.RegisterFilter("Saved Jira filter to report on instead of the project, by ID or name")
+	flags.RegisterJQL()
+	flags.RegisterColumns()
 	flag.Parse()
 
-	// Validate required flags
-	if *month == "" {
-		fmt.Println("Error: Month is required")
-		flag.Usage()
+	if err := flags.Apply(); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Parse month
-	monthTime, err := time.Parse("2006.01", *month)
+	// Parse the reporting period, one of -month, -week, -quarter or -from/-to
+	period, err := report.ParsePeriod(report.PeriodFlags{Month: *month, Week: *week, Quarter: *quarter, From: *from, To: *to}, cfg.Location)
 	if err != nil {
-		fmt.Printf("Error: Invalid month format. Use YYYY.MM\n")
+		fmt.Printf("Error: %v\n", err)
+		flag.Usage()
 		os.Exit(1)
 	}
-	monthStart := monthTime
-	monthEnd := monthStart.AddDate(0, 1, 0)
-	log.Printf("Filtering issues for month: %s to %s", monthStart.Format("2006-01-02"), monthEnd.Format("2006-01-02"))
+	log.Printf("Filtering issues for %s: %s to %s", period.Label(), period.Start.Format("2006-01-02"), period.End.Format("2006-01-02"))
+
+	// Cancel Jira calls on Ctrl+C and, if requested, after the timeout
+	ctx, cancel := flags.Context()
+	defer cancel()
 
 	// Create Jira service
-	jiraService, err := jiraservice.NewJiraService(cfg.JiraURL, cfg.JiraUsername, cfg.JiraAPIToken, cfg.JiraEpicField, cfg.JiraSPField)
+	jiraService, err := flags.NewJiraService()
 	if err != nil {
 		log.Fatalf("Failed to create Jira service: %v", err)
 	}
 
-	// Query issues that were in 'In Progress' state during the specified month
-	// We use JQL with updated date range to find issues modified during the month
-	filtered, err := jiraService.GetIssuesInProgressDuringMonth(cfg.ProjectKey, monthStart, monthEnd, []string{"Bug", "Story", "Task"})
+	// Query issues that were in progress at any time during the period, from
+	// the project or from all issues of the filter whatever their type
+	filter, err := flags.FindFilter(ctx, jiraService)
 	if err != nil {
-		log.Fatalf("Failed to get issues in progress: %v", err)
+		log.Fatalf("Failed to get filter: %v", err)
+	}
+	var filtered []jiraservice.Issue
+	scope := ""
+	if filter != nil {
+		scope = fmt.Sprintf(" in '%s'", filter.Name)
+		filtered, err = jiraService.GetFilterIssuesInProgressDuringWithContext(ctx, filter, period.Start, period.End, nil)
+		if err != nil {
+			log.Fatalf("Failed to get issues in progress: %v", err)
+		}
+	} else {
+		filtered, err = jiraService.GetIssuesInProgressDuringWithContext(ctx, cfg.ProjectKey, period.Start, period.End, []string{"Bug", "Story", "Task"})
+		if err != nil {
+			log.Fatalf("Failed to get issues in progress: %v", err)
+		}
 	}
 
-	// split issues into two lists: Closed and all others
+	// split issues into two lists: done and all others
 	closedIssues := []jiraservice.Issue{}
 	openIssues := []jiraservice.Issue{}
 
 	for _, issue := range filtered {
-		if issue.Status == "Closed" {
+		if issue.StatusCategory == jiraservice.StatusDone {
 			closedIssues = append(closedIssues, issue)
 		} else {
 			openIssues = append(openIssues, issue)
 		}
 	}
 
-	if *debugMode {
+	if flags.Debug {
 		// Print debug information
-		fmt.Printf("Found %d issues in 'In Progress' during %s\n", len(filtered), *month)
+		fmt.Printf("Found %d issues%s in 'In Progress' during %s\n", len(filtered), scope, period.Label())
 
 		logIssuesTable(fmt.Sprintf("\nClosed Issues (%d):", len(closedIssues)), closedIssues)
 		logIssuesTable(fmt.Sprintf("\nOpen Issues (%d):", len(openIssues)), openIssues)
+		logFlowMetrics(filtered)
 
 		fmt.Printf("\nTotal issues: %d\n", len(filtered))
 	} else {
 		// Create Word document
 		doc := word.NewDocument()
 
-		addTableToDocument(doc, fmt.Sprintf("Closed Issues During %s", monthStart.Format("January 2006")), closedIssues)
-		addTableToDocument(doc, fmt.Sprintf("Issues were in work but not Closed during %s", monthStart.Format("January 2006")), openIssues)
+		addTableToDocument(doc, fmt.Sprintf("Closed Issues%s During %s", scope, period.Label()), closedIssues, flags.Columns)
+		addTableToDocument(doc, fmt.Sprintf("Issues%s were in work but not Closed during %s", scope, period.Label()), openIssues, flags.Columns)
+		addFlowMetricsToDocument(doc, filtered)
 
-		// output file has format some_file.docx. Insert formatted date "yyyy-mm" before .docx
-		if outputFile != nil {
-			*outputFile = fmt.Sprintf("%s - %s.docx", (*outputFile)[:len(*outputFile)-5], monthStart.Format("2006-01"))
-		}
+		// output file has format some_file.docx. Insert the period, e.g. "yyyy-mm" or "yyyy-Www", before .docx
+		flags.Output = fmt.Sprintf("%s - %s.docx", flags.Output[:len(flags.Output)-5], period.FileSuffix())
 
 		// Save the document
-		err = doc.SaveDocumentToFile(outputFile)
+		err = doc.SaveDocumentToFile(&flags.Output)
 		if err != nil {
 			log.Fatalf("Failed to save document: %v", err)
 		}
 
-		fmt.Printf("Created document '%s' with %d issues\n", *outputFile, len(filtered))
+		fmt.Printf("Created document '%s' with %d issues\n", flags.Output, len(filtered))
 	}
 }
 
@@ -110,11 +124,11 @@ func logIssuesTable(header string, lines []jiraservice.Issue) {
 	fmt.Println(header)
 	for _, issue := range lines {
 		fmt.Printf("%-8s|%-12s|%-80s|%-40s|%.1f|%-12s\n",
-			issue.Type, issue.Key, truncate(issue.Summary, 80), truncate(issue.Epic, 40), issue.StoryPoints, issue.Status)
+			issue.Type, issue.Key, cli.Truncate(issue.Summary, 80), cli.Truncate(issue.Epic, 40), issue.StoryPoints, issue.Status)
 	}
 }
 
-func addTableToDocument(doc *word.Doc, headingText string, tableContent []jiraservice.Issue) {
+func addTableToDocument(doc *word.Doc, headingText string, tableContent []jiraservice.Issue, columns report.Columns) {
 
 	// Headers
 	headers := []string{"Type", "ID", "Description", "Epic", "SP"}
@@ -122,7 +136,8 @@ func addTableToDocument(doc *word.Doc, headingText string, tableContent []jirase
 	doc.AddHeading(1, headingText)
 
 	closedIssuesTable := word.NewTable(&doc.WordDocument)
-	closedIssuesTable.AddHeaderRow(headers)
+	closedIssuesTable.SetLeftAligned(append([]int{2, 3}, columns.LeftAligned(len(headers))...)...)
+	closedIssuesTable.AddHeaderRow(append(headers, columns.Headers()...))
 
 	// Add issue rows
 	for _, issue := range tableContent {
@@ -133,6 +148,49 @@ func addTableToDocument(doc *word.Doc, headingText string, tableContent []jirase
 			issue.Epic,
 			strconv.FormatFloat(issue.StoryPoints, 'f', 1, 64),
 		}
-		closedIssuesTable.AddDataRow(data)
+		closedIssuesTable.AddDataRow(append(data, columns.Values(issue)...))
+	}
+}
+
+func logFlowMetrics(issues []jiraservice.Issue) {
+	completed := report.CompletedIssues(issues)
+	fmt.Printf("\nFlow metrics (%d):\n", len(completed))
+	for _, issue := range completed {
+		fmt.Printf("%-12s|cycle %-7s|lead %-7s|%s\n",
+			issue.Key, report.FormatDays(issue.Flow.CycleTime), report.FormatDays(issue.Flow.LeadTime),
+			report.FormatTimeInStatus(issue.Flow.TimeInStatus))
+	}
+	fmt.Println(report.SummarizeFlow(issues))
+}
+
+// addFlowMetricsToDocument adds the cycle and lead times of the completed
+// issues, with their time in each status
+func addFlowMetricsToDocument(doc *word.Doc, issues []jiraservice.Issue) {
+	doc.AddHeading(1, "Flow metrics")
+	doc.AddParagraph(report.SummarizeFlow(issues).String())
+
+	completed := report.CompletedIssues(issues)
+	if len(completed) == 0 {
+		return
+	}
+
+	table := word.NewTable(&doc.WordDocument)
+	table.SetLeftAligned(2, 7)
+	table.AddHeaderRow([]string{"Type", "ID", "Description", "Started", "Done", "Cycle time", "Lead time", "Time in status"})
+	for _, issue := range completed {
+		started := ""
+		if !issue.Flow.Started.IsZero() {
+			started = issue.Flow.Started.Format("2006-01-02")
+		}
+		table.AddDataRow([]string{
+			issue.Type,
+			issue.Key,
+			issue.Summary,
+			started,
+			issue.Flow.Done.Format("2006-01-02"),
+			report.FormatDays(issue.Flow.CycleTime),
+			report.FormatDays(issue.Flow.LeadTime),
+			report.FormatTimeInStatus(issue.Flow.TimeInStatus),
+		})
 	}
 }
@@ -6,20 +6,15 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
+	"go-word-create/internal/cli"
 	"go-word-create/internal/config"
 	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/report"
 	"go-word-create/internal/word"
 )
 
-// truncate cuts a string if it's longer than maxLen and adds "..." at the end
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen-3] + "..."
-}
-
 func main() {
 	// Load configuration from .env file
 	cfg, err := config.Load()
@@ -28,67 +23,179 @@ func main() {
 	}
 
 	// Define command line flags
-	sprintName := flag.String("sprint", "", "Sprint name (required)")
-	outputFile := flag.String("output", cfg.OutputFile, "Output file name")
-	debugMode := flag.Bool("debug", false, "Debug mode: print data without generating Word document")
+	sprintName := flag.String("sprint", "", "Sprint ID, name or name prefix (case-insensitive), \"active\" or \"last-closed\"")
+	flags := cli.RegisterFlags(cfg)
+	flags.RegisterFilter("Saved Jira filter to report on instead of a sprint, by ID or name")
+	flags.RegisterJQL()
+	flags.RegisterColumns()
 	flag.Parse()
 
+	if err := flags.Apply(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Validate required flags
-	if *sprintName == "" {
-		fmt.Println("Error: Sprint name is required")
+	if (*sprintName == "") == (flags.Filter == "") {
+		fmt.Println("Error: exactly one of -sprint or -filter is required")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	// Cancel Jira calls on Ctrl+C and, if requested, after the timeout
+	ctx, cancel := flags.Context()
+	defer cancel()
+
 	// Create Jira service
-	jiraService, err := jiraservice.NewJiraService(cfg.JiraURL, cfg.JiraUsername, cfg.JiraAPIToken, cfg.JiraEpicField, cfg.JiraSPField)
+	jiraService, err := flags.NewJiraService()
 	if err != nil {
 		log.Fatalf("Failed to create Jira service: %v", err)
 	}
 
-	// Get issues from sprint
-	issues, err := jiraService.GetSprintIssues(cfg.ProjectKey, cfg.BoardName, *sprintName, []string{"Bug", "Feature", "Task"})
+	// A filter is reported as a single table of its issues whatever their
+	// type, a sprint as the changes to its scope
+	if flags.Filter != "" {
+		filter, err := flags.FindFilter(ctx, jiraService)
+		if err != nil {
+			log.Fatalf("Failed to get filter: %v", err)
+		}
+		issues, err := jiraService.GetIssuesByJQLWithContext(ctx, filter.JQL, nil)
+		if err != nil {
+			log.Fatalf("Failed to get filter issues: %v", err)
+		}
+		sections := []report.ScopeSection{{Title: fmt.Sprintf("Issues in filter '%s'", filter.Name), Issues: issues}}
+		writeReport(sections, nil, flags.Columns, flags.Debug, &flags.Output)
+		return
+	}
+
+	sprint, err := jiraService.FindSprintWithContext(ctx, cfg.BoardName, *sprintName)
 	if err != nil {
-		log.Fatalf("Failed to get sprint issues: %v", err)
+		log.Fatalf("Failed to find sprint: %v", err)
+	}
+	scope, err := jiraService.GetSprintScopeWithContext(ctx, cfg.ProjectKey, sprint, []string{"Bug", "Feature", "Task"})
+	if err != nil {
+		log.Fatalf("Failed to get sprint scope: %v", err)
+	}
+	log.Printf("Sprint '%s' ran from %s to %s", sprint.Name, scope.Start.Format("2006-01-02 15:04"), scope.End.Format("2006-01-02 15:04"))
+
+	// The sprint history only annotates the report, so it may be partial
+	if err := jiraService.AddSprintHistoryWithContext(ctx, cfg.BoardName, scope); err != nil {
+		if ctx.Err() != nil {
+			log.Fatalf("Failed to get sprint history: %v", err)
+		}
+		log.Printf("warning: %v", err)
 	}
+	columns := append(report.Columns{report.SprintsColumn}, flags.Columns...)
 
-	if *debugMode {
+	writeReport(report.ScopeSections(scope), scope.Burndown, columns, flags.Debug, &flags.Output)
+}
+
+// writeReport prints the sections in debug mode, otherwise saves them as a
+// Word document with a table per section, followed by the burndown charts
+// when there is a burndown
+func writeReport(sections []report.ScopeSection, burndown []jiraservice.BurndownPoint, columns report.Columns, debugMode bool, outputFile *string) {
+	// Carried over issues are listed twice, count them once
+	keys := make(map[string]struct{})
+	for _, section := range sections {
+		for _, issue := range section.Issues {
+			keys[issue.Key] = struct{}{}
+		}
+	}
+	count := len(keys)
+
+	if debugMode {
 		// Print debug information
-		fmt.Printf("Found %d issues in sprint '%s'\n", len(issues), *sprintName)
-		fmt.Println("\nIssues:")
-		for _, issue := range issues {
-			// Truncate strings that are too long
-			fmt.Printf("%-8s|%-12s|%-80s|%-40s|%.1f\n",
-				issue.Type, issue.Key, truncate(issue.Summary, 80), truncate(issue.Epic, 40), issue.StoryPoints)
+		for _, section := range sections {
+			fmt.Printf("\n%s:\n", section.Heading())
+			for _, issue := range section.Issues {
+				// Truncate strings that are too long
+				fmt.Printf("%-8s|%-12s|%-80s|%-40s|%.1f|%-12s",
+					issue.Type, issue.Key, cli.Truncate(issue.Summary, 80), cli.Truncate(issue.Epic, 40), issue.StoryPoints, issue.Status)
+				if len(issue.Sprints) > 1 {
+					fmt.Printf("|%d sprints: %s", len(issue.Sprints), strings.Join(issue.Sprints, ", "))
+				}
+				fmt.Println()
+			}
 		}
-		fmt.Printf("\nTotal issues: %d\n", len(issues))
-	} else {
-		// Create Word document
-		doc := word.NewDocument()
-		table := word.NewTable(&doc.WordDocument)
-
-		// Add header row
-		headers := []string{"Type", "Key", "Summary", "Epic", "Story Points"}
-		table.AddHeaderRow(headers)
-
-		// Add issue rows
-		for _, issue := range issues {
-			data := []string{
-				issue.Type,
-				issue.Key,
-				issue.Summary,
-				issue.Epic,
-				strconv.FormatFloat(issue.StoryPoints, 'f', 1, 64),
+		fmt.Printf("\nTotal issues: %d\n", count)
+
+		if len(burndown) > 0 {
+			fmt.Printf("\nBurndown:\n")
+			for _, point := range burndown {
+				fmt.Printf("%-16s|scope %5.1f|completed %5.1f|remaining %5.1f|ideal %5.1f\n",
+					point.At.Format("2006-01-02 15:04"), point.Scope, point.Completed, point.Remaining(), point.Ideal)
 			}
-			table.AddDataRow(data)
 		}
+		return
+	}
 
-		// Save the document
-		err = doc.SaveDocumentToFile(outputFile)
-		if err != nil {
-			log.Fatalf("Failed to save document: %v", err)
+	// Create Word document
+	doc := word.NewDocument()
+	for _, section := range sections {
+		addTableToDocument(doc, section.Heading(), section.Issues, columns)
+	}
+	if len(burndown) > 0 {
+		addBurndownCharts(doc, burndown)
+	}
+
+	// Save the document
+	if err := doc.SaveDocumentToFile(outputFile); err != nil {
+		log.Fatalf("Failed to save document: %v", err)
+	}
+
+	fmt.Printf("Created document '%s' with %d issues\n", *outputFile, count)
+}
+
+// addTableToDocument adds a heading and a table of the issues, ending with
+// their story point total
+func addTableToDocument(doc *word.Doc, headingText string, issues []jiraservice.Issue, columns report.Columns) {
+	doc.AddHeading(1, headingText)
+	table := word.NewTable(&doc.WordDocument)
+
+	// Add header row
+	headers := []string{"Type", "Key", "Summary", "Epic", "Story Points"}
+	table.SetLeftAligned(append([]int{2, 3}, columns.LeftAligned(len(headers))...)...)
+	table.AddHeaderRow(append(headers, columns.Headers()...))
+
+	// Add issue rows
+	for _, issue := range issues {
+		data := []string{
+			issue.Type,
+			issue.Key,
+			issue.Summary,
+			issue.Epic,
+			strconv.FormatFloat(issue.StoryPoints, 'f', 1, 64),
 		}
+		table.AddDataRow(append(data, columns.Values(issue)...))
+	}
+
+	total := []string{"", "", "Total", "", strconv.FormatFloat(report.StoryPoints(issues), 'f', 1, 64)}
+	table.AddDataRow(append(total, make([]string, len(columns))...))
+}
 
-		fmt.Printf("Created document '%s' with %d issues\n", *outputFile, len(issues))
+// addBurndownCharts adds the burndown of the remaining points against the
+// ideal line, and the burnup of the completed points against the scope
+func addBurndownCharts(doc *word.Doc, burndown []jiraservice.BurndownPoint) {
+	days := make([]string, 0, len(burndown))
+	remaining := word.ChartSeries{Name: "Remaining"}
+	ideal := word.ChartSeries{Name: "Ideal", Dashed: true}
+	scope := word.ChartSeries{Name: "Scope"}
+	completed := word.ChartSeries{Name: "Completed"}
+	for i, point := range burndown {
+		// The sprint ends during the day of the last midnight sample
+		day := point.At.Format("Jan 2")
+		if i > 0 && i == len(burndown)-1 {
+			day = "End"
+		}
+		days = append(days, day)
+		remaining.Values = append(remaining.Values, point.Remaining())
+		ideal.Values = append(ideal.Values, point.Ideal)
+		scope.Values = append(scope.Values, point.Scope)
+		completed.Values = append(completed.Values, point.Completed)
 	}
+
+	doc.AddHeading(1, "Burndown")
+	doc.AddLineChart("Remaining story points", days, []word.ChartSeries{remaining, ideal})
+	doc.AddHeading(1, "Burnup")
+	doc.AddLineChart("Completed story points", days, []word.ChartSeries{completed, scope})
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"go-word-create/internal/cli"
+	"go-word-create/internal/config"
+	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/report"
+	"go-word-create/internal/word"
+)
+
+func main() {
+	// Load configuration from .env file
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Define command line flags
+	sprintCount := flag.Int("sprints", 6, "Number of most recent closed sprints to report on")
+	window := flag.Int("window", 3, "Number of sprints in the rolling average")
+	typeList := flag.String("types", "Bug,Feature,Story,Task", "Issue types counted, comma separated")
+	flags := cli.RegisterFlags(cfg)
+	flags.RegisterFilter("Saved Jira filter whose issues are counted instead of the project's, by ID or name")
+	flags.RegisterJQL()
+	flag.Parse()
+
+	if *sprintCount < 1 || *window < 1 {
+		fmt.Println("Error: -sprints and -window must be at least 1")
+		os.Exit(1)
+	}
+
+	issueTypes := cli.SplitList(*typeList)
+
+	if err := flags.Apply(); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Cancel Jira calls on Ctrl+C and, if requested, after the timeout
+	ctx, cancel := flags.Context()
+	defer cancel()
+
+	// Create Jira service
+	jiraService, err := flags.NewJiraService()
+	if err != nil {
+		log.Fatalf("Failed to create Jira service: %v", err)
+	}
+
+	// Count the issues of the project or of the filter
+	filter, err := flags.FindFilter(ctx, jiraService)
+	if err != nil {
+		log.Fatalf("Failed to get filter: %v", err)
+	}
+	var scopes []jiraservice.SprintScope
+	scope := ""
+	if filter != nil {
+		scope = fmt.Sprintf(" in '%s'", filter.Name)
+		scopes, err = jiraService.GetFilterClosedSprintScopesWithContext(ctx, filter, cfg.BoardName, *sprintCount, issueTypes)
+	} else {
+		scopes, err = jiraService.GetClosedSprintScopesWithContext(ctx, cfg.ProjectKey, cfg.BoardName, *sprintCount, issueTypes)
+	}
+	if err != nil {
+		log.Fatalf("Failed to get sprints: %v", err)
+	}
+	if len(scopes) == 0 {
+		log.Fatalf("Board '%s' has no closed sprints", cfg.BoardName)
+	}
+
+	velocities := report.Velocities(scopes, *window)
+	summary := report.SummarizeVelocity(velocities)
+
+	if flags.Debug {
+		// Print debug information
+		fmt.Printf("Velocity of the last %d sprints%s\n", len(velocities), scope)
+		fmt.Printf("%-30s|%9s|%9s|%9s|%9s|%9s\n", "Sprint", "Commit SP", "Done SP", "Commit #", "Done #", "Rolling")
+		for _, v := range velocities {
+			fmt.Printf("%-30s|%9.1f|%9.1f|%9d|%9d|%9.1f\n",
+				v.Sprint, v.CommittedPoints, v.CompletedPoints, v.CommittedIssues, v.CompletedIssues, v.RollingAverage)
+		}
+		fmt.Printf("\n%s\n", summary)
+		return
+	}
+
+	// Create Word document
+	doc := word.NewDocument()
+	doc.AddHeading(1, fmt.Sprintf("Velocity of the last %d sprints%s", len(velocities), scope))
+	doc.AddParagraph(summary.String())
+
+	table := word.NewTable(&doc.WordDocument)
+	table.SetLeftAligned(0)
+	table.AddHeaderRow([]string{"Sprint", "Committed SP", "Completed SP", "Committed Issues", "Completed Issues", fmt.Sprintf("Rolling Average (%d)", *window)})
+	categories := make([]string, 0, len(velocities))
+	committed := word.ChartSeries{Name: "Committed"}
+	completed := word.ChartSeries{Name: "Completed"}
+	for _, v := range velocities {
+		table.AddDataRow([]string{
+			v.Sprint,
+			strconv.FormatFloat(v.CommittedPoints, 'f', 1, 64),
+			strconv.FormatFloat(v.CompletedPoints, 'f', 1, 64),
+			strconv.Itoa(v.CommittedIssues),
+			strconv.Itoa(v.CompletedIssues),
+			strconv.FormatFloat(v.RollingAverage, 'f', 1, 64),
+		})
+		categories = append(categories, v.Sprint)
+		committed.Values = append(committed.Values, v.CommittedPoints)
+		completed.Values = append(completed.Values, v.CompletedPoints)
+	}
+
+	doc.AddHeading(2, "Committed vs completed story points")
+	doc.AddBarChart("Story points per sprint", categories, []word.ChartSeries{committed, completed})
+
+	// Save the document
+	if err := doc.SaveDocumentToFile(&flags.Output); err != nil {
+		log.Fatalf("Failed to save document: %v", err)
+	}
+
+	fmt.Printf("Created document '%s' with %d sprints\n", flags.Output, len(velocities))
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"go-word-create/internal/config"
+	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/jql"
+	"go-word-create/internal/report"
+)
+
+// Flags are the command line flags shared by the report commands
+type Flags struct {
+	Output  string
+	Debug   bool
+	Record  string
+	Replay  string
+	NoCache bool
+	Timeout time.Duration
+	// Filter is the saved Jira filter given with -filter, by ID or name
+	Filter string
+
+	// ExtraJQL and Columns are set by Apply from the -jql and -columns
+	// flags, when the command registered them
+	ExtraJQL jql.Clause
+	Columns  report.Columns
+
+	cfg        *config.Config
+	jql        *string
+	columnList *string
+}
+
+// RegisterFlags defines the shared flags on the default flag set. The
+// command defines its own flags and then calls flag.Parse and Apply.
+func RegisterFlags(cfg *config.Config) *Flags {
+	f := &Flags{cfg: cfg}
+	flag.StringVar(&f.Output, "output", cfg.OutputFile, "Output file name")
+	flag.BoolVar(&f.Debug, "debug", false, "Debug mode: print data without generating Word document")
+	flag.StringVar(&f.Record, "record", "", "Record Jira traffic to the given cassette directory")
+	flag.StringVar(&f.Replay, "replay", "", "Replay Jira traffic from the given cassette directory instead of calling Jira")
+	flag.BoolVar(&f.NoCache, "no-cache", false, "Always fetch boards, sprints and epics from Jira instead of the local cache")
+	flag.DurationVar(&f.Timeout, "timeout", 0, "Abort if fetching from Jira takes longer than this, e.g. 5m (0 means no limit)")
+	return f
+}
+
+// RegisterJQL defines -jql, extra JQL the report issues must also match
+func (f *Flags) RegisterJQL() {
+	f.jql = flag.String("jql", "", "Extra JQL the issues must also match, e.g. 'component = API'")
+}
+
+// RegisterFilter defines -filter, a saved Jira filter scoping the report.
+// The usage says what the filter replaces.
+func (f *Flags) RegisterFilter(usage string) {
+	flag.StringVar(&f.Filter, "filter", "", usage)
+}
+
+// RegisterColumns defines -columns, the extra columns of the issue tables
+func (f *Flags) RegisterColumns() {
+	f.columnList = flag.String("columns", f.cfg.ReportColumns, "Extra table columns, comma separated: "+strings.Join(report.ColumnNames(), ", "))
+}
+
+// Apply validates the parsed flags and applies them to the configuration
+func (f *Flags) Apply() error {
+	if f.columnList != nil {
+		columns, err := report.ParseColumns(*f.columnList)
+		if err != nil {
+			return err
+		}
+		f.Columns = append(columns, report.CustomFieldColumns(f.cfg.CustomFields)...)
+	}
+
+	if f.jql != nil {
+		clause, err := jql.Raw(*f.jql)
+		if err != nil {
+			return fmt.Errorf("invalid -jql: %w", err)
+		}
+		f.ExtraJQL = clause
+	}
+
+	return useCassette(f.cfg, f.Record, f.Replay)
+}
+
+// Context returns the context of the Jira calls, cancelled on Ctrl+C and,
+// if requested, after the timeout
+func (f *Flags) Context() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if f.Timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// NewJiraService creates the Jira service the flags ask for
+func (f *Flags) NewJiraService() (*jiraservice.JiraService, error) {
+	return NewJiraService(f.cfg, !f.NoCache, f.ExtraJQL)
+}
+
+// FindFilter returns the saved filter given with -filter, nil if none was
+func (f *Flags) FindFilter(ctx context.Context, jiraService *jiraservice.JiraService) (*jiraservice.Filter, error) {
+	if f.Filter == "" {
+		return nil, nil
+	}
+	filter, err := jiraService.FindFilterWithContext(ctx, f.Filter)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Using filter '%s': %s", filter.Name, filter.JQL)
+	return filter, nil
+}
+
+// SplitList splits a comma separated flag value, dropping empty entries
+func SplitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Truncate cuts a string if it's longer than maxLen and adds "..." at the end
+func Truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
@@ -0,0 +1,122 @@
+// Package cli holds the setup and console helpers shared by the report
+// commands
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"go-word-create/internal/config"
+	"go-word-create/internal/jiraservice"
+	"go-word-create/internal/jql"
+)
+
+// NewJiraService connects to Jira, or to the JSON fixtures when configured.
+// Report issues are limited to those matching extraJQL.
+func NewJiraService(cfg *config.Config, useCache bool, extraJQL jql.Clause) (*jiraservice.JiraService, error) {
+	if cfg.FixturesDir != "" {
+		fake, err := jiraservice.LoadFakeClient(cfg.FixturesDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Using Jira fixtures from %s", cfg.FixturesDir)
+		return jiraservice.NewJiraServiceWithClient(fake, cfg.JiraURL, cfg.JiraEpicField, cfg.JiraSPField,
+			jiraservice.WithConcurrency(cfg.JiraConcurrency),
+			jiraservice.WithCustomFields(customFields(cfg)),
+			jiraservice.WithActiveStatuses(cfg.JiraActiveStatuses),
+			jiraservice.WithDoneStatuses(cfg.JiraDoneStatuses),
+			jiraservice.WithLocation(cfg.Location),
+			jiraservice.WithJQL(extraJQL)), nil
+	}
+
+	cassetteMode, err := jiraservice.ParseCassetteMode(cfg.CassetteMode)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials are not needed when replaying a cassette
+	if cassetteMode != jiraservice.CassetteReplay {
+		if err := cfg.CheckCredentials(); err != nil {
+			return nil, err
+		}
+	}
+	if cassetteMode != jiraservice.CassetteOff {
+		log.Printf("Jira traffic %s mode, cassette %s", cassetteMode, cfg.CassetteDir)
+	}
+
+	opts := []jiraservice.Option{
+		jiraservice.WithConcurrency(cfg.JiraConcurrency),
+		jiraservice.WithCustomFields(customFields(cfg)),
+		jiraservice.WithActiveStatuses(cfg.JiraActiveStatuses),
+		jiraservice.WithDoneStatuses(cfg.JiraDoneStatuses),
+		jiraservice.WithLocation(cfg.Location),
+		jiraservice.WithJQL(extraJQL),
+	}
+
+	// The cache would hide requests from a cassette, so it is only used
+	// against live Jira
+	if useCache && cassetteMode == jiraservice.CassetteOff {
+		cache, ttl, err := newCache(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, jiraservice.WithCache(cache, ttl))
+	}
+
+	retry := jiraservice.DefaultRetryPolicy()
+	retry.MaxRetries = cfg.JiraMaxRetries
+	retry.MaxElapsed = cfg.JiraRetryMaxWait
+
+	opts = append(opts,
+		jiraservice.WithCassette(cfg.CassetteDir, cassetteMode),
+		jiraservice.WithRetry(retry))
+
+	return jiraservice.NewJiraService(cfg.JiraURL, cfg.JiraUsername, cfg.JiraAPIToken, cfg.JiraEpicField, cfg.JiraSPField, opts...)
+}
+
+// useCassette applies the -record and -replay flags to the configuration
+func useCassette(cfg *config.Config, recordDir, replayDir string) error {
+	if recordDir != "" && replayDir != "" {
+		return errors.New("-record and -replay cannot be used together")
+	}
+	if recordDir != "" {
+		cfg.CassetteDir, cfg.CassetteMode = recordDir, string(jiraservice.CassetteRecord)
+	}
+	if replayDir != "" {
+		cfg.CassetteDir, cfg.CassetteMode = replayDir, string(jiraservice.CassetteReplay)
+	}
+	return nil
+}
+
+// customFields converts the configured custom fields for the field mapper
+func customFields(cfg *config.Config) []jiraservice.CustomField {
+	fields := make([]jiraservice.CustomField, 0, len(cfg.CustomFields))
+	for _, f := range cfg.CustomFields {
+		fields = append(fields, jiraservice.CustomField{Name: f.Name, ID: f.FieldID})
+	}
+	return fields
+}
+
+// newCache opens the on-disk cache for boards, closed sprints and epics
+func newCache(cfg *config.Config) (jiraservice.Cache, jiraservice.CacheTTL, error) {
+	dir := cfg.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = jiraservice.DefaultCacheDir(); err != nil {
+			return nil, jiraservice.CacheTTL{}, fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+	}
+
+	ttl := jiraservice.DefaultCacheTTL()
+	if cfg.CacheTTLBoards > 0 {
+		ttl.Boards = cfg.CacheTTLBoards
+	}
+	if cfg.CacheTTLSprints > 0 {
+		ttl.ClosedSprints = cfg.CacheTTLSprints
+	}
+	if cfg.CacheTTLEpics > 0 {
+		ttl.Epics = cfg.CacheTTLEpics
+	}
+
+	return jiraservice.NewFileCache(dir), ttl, nil
+}
@@ -1,8 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded zone database, for systems without one
+	_ "time/tzdata"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +22,44 @@ type Config struct {
 	OutputFile    string
 	JiraEpicField string
 	JiraSPField   string
+	// FixturesDir, when set, serves Jira data from JSON fixtures instead of
+	// a live instance
+	FixturesDir string
+	// CassetteDir and CassetteMode ("record" or "replay") capture or replay
+	// all Jira HTTP traffic
+	CassetteDir  string
+	CassetteMode string
+	// JiraMaxRetries and JiraRetryMaxWait bound the retries of failed Jira
+	// calls: the number of retries and the total time spent retrying
+	JiraMaxRetries   int
+	JiraRetryMaxWait time.Duration
+	// JiraConcurrency is the number of sprints fetched from Jira in parallel
+	JiraConcurrency int
+	// CacheDir holds cached boards, closed sprints and epics; empty uses the
+	// per-user cache directory
+	CacheDir string
+	// CacheTTL* bound how long each kind of cached entity is trusted; zero
+	// uses the built-in default
+	CacheTTLBoards  time.Duration
+	CacheTTLSprints time.Duration
+	CacheTTLEpics   time.Duration
+	// CustomFields are extra Jira fields added to the reports, in order
+	CustomFields []CustomField
+	// JiraActiveStatuses and JiraDoneStatuses are the statuses in which an
+	// issue counts as being worked on and as done; empty uses Jira's status
+	// categories
+	JiraActiveStatuses []string
+	JiraDoneStatuses   []string
+	// ReportColumns is the default list of optional report columns
+	ReportColumns string
+	// Location is the time zone report periods and dates are in
+	Location *time.Location
+}
+
+// CustomField maps a Jira field ID to the column name used in reports
+type CustomField struct {
+	Name    string
+	FieldID string
 }
 
 // Load reads the configuration from environment variables
@@ -29,8 +73,6 @@ func Load() (*Config, error) {
 	// Check required environment variables
 	requiredVars := []string{
 		"JIRA_URL",
-		"JIRA_USERNAME",
-		"JIRA_API_TOKEN",
 		"JIRA_BOARD_NAME",
 		"JIRA_PROJECT_KEY",
 	}
@@ -50,11 +92,79 @@ func Load() (*Config, error) {
 		OutputFile:    getEnvWithDefault("DEFAULT_OUTPUT_FILE", "sprint-issues.docx"),
 		JiraEpicField: getEnvWithDefault("JIRA_EPIC_FIELD", "customfield_14500"),
 		JiraSPField:   getEnvWithDefault("JIRA_SP_FIELD", "customfield_10004"),
+		FixturesDir:   os.Getenv("JIRA_FIXTURES_DIR"),
+		CassetteDir:   os.Getenv("JIRA_CASSETTE_DIR"),
+		CassetteMode:  os.Getenv("JIRA_CASSETTE_MODE"),
+		CacheDir:      os.Getenv("JIRA_CACHE_DIR"),
+		ReportColumns: os.Getenv("REPORT_COLUMNS"),
+	}
+
+	if config.JiraMaxRetries, err = getEnvInt("JIRA_MAX_RETRIES", 5); err != nil {
+		return nil, err
+	}
+	if config.JiraRetryMaxWait, err = getEnvDuration("JIRA_RETRY_MAX_WAIT", 2*time.Minute); err != nil {
+		return nil, err
+	}
+
+	if config.JiraConcurrency, err = getEnvInt("JIRA_CONCURRENCY", 4); err != nil {
+		return nil, err
+	}
+	if config.CacheTTLBoards, err = getEnvDuration("JIRA_CACHE_TTL_BOARDS", 0); err != nil {
+		return nil, err
+	}
+	if config.CacheTTLSprints, err = getEnvDuration("JIRA_CACHE_TTL_SPRINTS", 0); err != nil {
+		return nil, err
+	}
+	if config.CacheTTLEpics, err = getEnvDuration("JIRA_CACHE_TTL_EPICS", 0); err != nil {
+		return nil, err
+	}
+
+	config.JiraActiveStatuses = getEnvList("JIRA_ACTIVE_STATUSES", nil)
+	config.JiraDoneStatuses = getEnvList("JIRA_DONE_STATUSES", nil)
+
+	if config.Location, err = getEnvLocation("REPORT_TIMEZONE"); err != nil {
+		return nil, err
+	}
+
+	if config.CustomFields, err = parseCustomFields(os.Getenv("JIRA_CUSTOM_FIELDS")); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// CheckCredentials returns an error unless the Jira credentials are set.
+// Load does not require them because fixtures and replayed cassettes, which
+// may be selected by command line flags, work without.
+func (c *Config) CheckCredentials() error {
+	if c.JiraUsername == "" {
+		return errors.New("required environment variable JIRA_USERNAME is not set")
+	}
+	if c.JiraAPIToken == "" {
+		return errors.New("required environment variable JIRA_API_TOKEN is not set")
+	}
+	return nil
+}
+
+// parseCustomFields parses a comma separated list of Name=fieldID pairs,
+// e.g. "Team=customfield_10100,Sprint Goal=customfield_10200"
+func parseCustomFields(value string) ([]CustomField, error) {
+	var fields []CustomField
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, id, ok := strings.Cut(entry, "=")
+		name, id = strings.TrimSpace(name), strings.TrimSpace(id)
+		if !ok || name == "" || id == "" {
+			return nil, fmt.Errorf("invalid value for JIRA_CUSTOM_FIELDS: %q is not Name=fieldID", entry)
+		}
+		fields = append(fields, CustomField{Name: name, FieldID: id})
+	}
+	return fields, nil
+}
+
 // getEnvWithDefault returns environment variable value or default if not set
 func getEnvWithDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -62,3 +172,59 @@ func getEnvWithDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList returns environment variable value split on commas, or default
+// if not set
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvInt returns environment variable value as int or default if not set
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return i, nil
+}
+
+// getEnvLocation returns the time zone named by the environment variable
+// (IANA name, e.g. "Europe/Kyiv") or the local time zone if not set
+func getEnvLocation(key string) (*time.Location, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return loc, nil
+}
+
+// getEnvDuration returns environment variable value as duration (e.g. "90s")
+// or default if not set
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,153 @@
+package jiraservice
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// storyPointFieldNames are the names changelogs use for the story points
+// field, which unlike the issue fields are not IDs
+var storyPointFieldNames = map[string]struct{}{
+	"story points":         {},
+	"story point estimate": {},
+}
+
+// BurndownPoint is the state of the sprint's story points at an instant
+type BurndownPoint struct {
+	At time.Time
+	// Scope is the points of the issues in the sprint, Completed those of
+	// the ones that were done
+	Scope     float64
+	Completed float64
+	// Ideal is what would remain burning down steadily from the points at
+	// the start to zero at the planned end
+	Ideal float64
+}
+
+// Remaining returns the points of the sprint left to do
+func (p BurndownPoint) Remaining() float64 {
+	return p.Scope - p.Completed
+}
+
+// pointsChange is a change of the story points read from the changelog
+type pointsChange struct {
+	at       time.Time
+	from, to float64
+}
+
+// pointsChanges returns the story points changes of the changelog, oldest
+// first. The field is matched by its ID or its usual names.
+func pointsChanges(changelog *jira.Changelog, spField string) []pointsChange {
+	if changelog == nil {
+		return nil
+	}
+
+	var changes []pointsChange
+	for _, history := range changelog.Histories {
+		at, err := parseChangelogTime(history.Created)
+		if err != nil {
+			log.Printf("warning: could not parse changelog timestamp %s: %v", history.Created, err)
+			continue
+		}
+		for _, item := range history.Items {
+			_, named := storyPointFieldNames[strings.ToLower(item.Field)]
+			if named || strings.EqualFold(item.Field, spField) {
+				changes = append(changes, pointsChange{at: at, from: parsePoints(item.FromString), to: parsePoints(item.ToString)})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].at.Before(changes[j].at)
+	})
+	return changes
+}
+
+// parsePoints reads story points from a changelog value, empty meaning none
+func parsePoints(s string) float64 {
+	points, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return points
+}
+
+// pointsAt returns the story points at the instant t from the changes and
+// the current points
+func pointsAt(changes []pointsChange, current float64, t time.Time) float64 {
+	points := current
+	if len(changes) > 0 {
+		points = changes[0].from
+	}
+	for _, change := range changes {
+		if change.at.After(t) {
+			break
+		}
+		points = change.to
+	}
+	return points
+}
+
+// burndownIssue is the history of an issue the burndown is computed from
+type burndownIssue struct {
+	membership []MembershipInterval
+	timeline   []StatusInterval
+	points     []pointsChange
+	current    float64
+}
+
+// burndownTimes returns the instants the burndown is sampled at: the start,
+// the beginning of every day in loc after it, and the end, all in loc
+func burndownTimes(start, end time.Time, loc *time.Location) []time.Time {
+	start, end = start.In(loc), end.In(loc)
+	times := []time.Time{start}
+	y, m, d := start.Date()
+	for day := time.Date(y, m, d+1, 0, 0, 0, 0, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		times = append(times, day)
+	}
+	if end.After(start) {
+		times = append(times, end)
+	}
+	return times
+}
+
+// buildBurndown computes the sprint's points over [start, end]. The ideal
+// line reaches zero at the planned end, which may differ from the end.
+func (s *JiraService) buildBurndown(issues []burndownIssue, start, end, plannedEnd time.Time) []BurndownPoint {
+	loc := s.location
+	if loc == nil {
+		loc = start.Location()
+	}
+
+	var points []BurndownPoint
+	for _, t := range burndownTimes(start, end, loc) {
+		point := BurndownPoint{At: t}
+		for _, issue := range issues {
+			if !inSprintAt(issue.membership, t) {
+				continue
+			}
+			sp := pointsAt(issue.points, issue.current, t)
+			point.Scope += sp
+			if s.statuses.Category(statusAt(issue.timeline, t)) == StatusDone {
+				point.Completed += sp
+			}
+		}
+		points = append(points, point)
+	}
+
+	if len(points) == 0 {
+		return nil
+	}
+	initial := points[0].Scope
+	length := plannedEnd.Sub(start)
+	for i := range points {
+		left := 0.0
+		if length > 0 {
+			left = 1 - float64(points[i].At.Sub(start))/float64(length)
+		}
+		points[i].Ideal = initial * max(left, 0)
+	}
+	return points
+}
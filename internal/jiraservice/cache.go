@@ -0,0 +1,202 @@
+package jiraservice
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache keeps Jira lookups that rarely change (boards, closed sprints,
+// epics) between runs
+type Cache interface {
+	// Get loads the entry stored under key into v and returns the time it
+	// was stored. It reports false when there is no entry or the entry is
+	// older than maxAge.
+	Get(key string, maxAge time.Duration, v interface{}) (time.Time, bool, error)
+	// Set stores v under key
+	Set(key string, v interface{}) error
+	// Delete removes the entry stored under key, if any
+	Delete(key string) error
+}
+
+// CacheTTL is how long each kind of cached entity is trusted
+type CacheTTL struct {
+	// Boards applies to board lookups by name
+	Boards time.Duration
+	// ClosedSprints applies to the closed sprints of a board. Active and
+	// future sprints are always fetched fresh.
+	ClosedSprints time.Duration
+	// Epics applies to the epics of a project. Within the TTL the cache is
+	// still dropped as soon as Jira reports an epic updated after it was
+	// stored.
+	Epics time.Duration
+}
+
+// DefaultCacheTTL returns the TTLs used when none are configured
+func DefaultCacheTTL() CacheTTL {
+	return CacheTTL{
+		Boards:        7 * 24 * time.Hour,
+		ClosedSprints: 7 * 24 * time.Hour,
+		Epics:         24 * time.Hour,
+	}
+}
+
+// FileCache is a Cache storing one JSON file per entry in a directory
+type FileCache struct {
+	dir string
+}
+
+// fileCacheEntry is the on-disk format of a FileCache entry
+type fileCacheEntry struct {
+	StoredAt time.Time       `json:"storedAt"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// NewFileCache creates a file cache in dir. The directory is created on the
+// first write.
+func NewFileCache(dir string) *FileCache {
+	return &FileCache{dir: dir}
+}
+
+// DefaultCacheDir returns the per-user cache directory of this tool
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-word-create"), nil
+}
+
+// path returns the file of the entry. Keys hold board names and other user
+// input, so every byte but letters, digits, '.' and '-' is escaped as _XX;
+// the escaping is reversible and distinct keys never share a file.
+func (c *FileCache) path(key string) string {
+	var name strings.Builder
+	for i := 0; i < len(key); i++ {
+		b := key[i]
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '.' || b == '-' {
+			name.WriteByte(b)
+		} else {
+			fmt.Fprintf(&name, "_%02X", b)
+		}
+	}
+	return filepath.Join(c.dir, name.String()+".json")
+}
+
+func (c *FileCache) Get(key string, maxAge time.Duration, v interface{}) (time.Time, bool, error) {
+	data, err := os.ReadFile(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var entry fileCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		// A corrupt entry is a miss, it gets overwritten on the next Set
+		return time.Time{}, false, nil
+	}
+	if time.Since(entry.StoredAt) > maxAge {
+		return time.Time{}, false, nil
+	}
+	if err := json.Unmarshal(entry.Value, v); err != nil {
+		return time.Time{}, false, nil
+	}
+	return entry.StoredAt, true, nil
+}
+
+func (c *FileCache) Set(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(fileCacheEntry{StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write through a temporary file so concurrent runs never read a
+	// half-written entry
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(key))
+}
+
+func (c *FileCache) Delete(key string) error {
+	err := os.Remove(c.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// cacheNamespace returns the prefix of the cache keys of the Jira instance
+// at baseURL, so instances sharing a cache directory never see each other's
+// boards, sprints or epics
+func cacheNamespace(baseURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimRight(strings.ToLower(baseURL), "/")))
+	return hex.EncodeToString(sum[:6])
+}
+
+// cacheKey returns the key an entry is stored under for this instance
+func (s *JiraService) cacheKey(key string) string {
+	return s.cacheNamespace + "-" + key
+}
+
+// cacheGet loads a cached entry, treating cache failures as misses
+func (s *JiraService) cacheGet(key string, maxAge time.Duration, v interface{}) (time.Time, bool) {
+	if s.cache == nil {
+		return time.Time{}, false
+	}
+	key = s.cacheKey(key)
+	storedAt, ok, err := s.cache.Get(key, maxAge, v)
+	if err != nil {
+		log.Printf("warning: failed to read cache entry %s: %v", key, err)
+		return time.Time{}, false
+	}
+	return storedAt, ok
+}
+
+// cacheSet stores an entry, logging rather than failing on cache errors
+func (s *JiraService) cacheSet(key string, v interface{}) {
+	if s.cache == nil {
+		return
+	}
+	key = s.cacheKey(key)
+	if err := s.cache.Set(key, v); err != nil {
+		log.Printf("warning: failed to write cache entry %s: %v", key, err)
+	}
+}
+
+// cacheDelete drops an entry that is known to be stale
+func (s *JiraService) cacheDelete(key string) {
+	if s.cache == nil {
+		return
+	}
+	key = s.cacheKey(key)
+	if err := s.cache.Delete(key); err != nil {
+		log.Printf("warning: failed to delete cache entry %s: %v", key, err)
+	}
+}
@@ -0,0 +1,115 @@
+package jiraservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// newCachedService returns a service on the fake client at baseURL, caching
+// in cache
+func newCachedService(fake *FakeClient, baseURL string, cache Cache) *JiraService {
+	return NewJiraServiceWithClient(fake, baseURL, "", "", WithCache(cache, DefaultCacheTTL()))
+}
+
+func TestCacheIsKeptPerJiraInstance(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	ctx := context.Background()
+
+	first := &FakeClient{Boards: []jira.Board{{ID: 1, Name: "Team Board"}}}
+	if _, err := newCachedService(first, "https://one.example.com", cache).GetBoardWithContext(ctx, "Team Board"); err != nil {
+		t.Fatalf("GetBoard on the first instance: %v", err)
+	}
+
+	// The second instance has no such board and must not get the first's
+	second := &FakeClient{}
+	if board, err := newCachedService(second, "https://two.example.com", cache).GetBoardWithContext(ctx, "Team Board"); err == nil {
+		t.Fatalf("got board %d from the other instance's cache, want an error", board.ID)
+	}
+
+	// The first instance still finds it in the cache
+	first.Boards = nil
+	if _, err := newCachedService(first, "https://one.example.com/", cache).GetBoardWithContext(ctx, "Team Board"); err != nil {
+		t.Errorf("GetBoard on the first instance from the cache: %v", err)
+	}
+}
+
+func TestBoardSprintsRefreshesWhenANewerSprintIsOpen(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	ctx := context.Background()
+
+	// Cached while the board had no open sprint
+	fake := &FakeClient{Sprints: map[int][]jira.Sprint{1: {{ID: 1, Name: "Sprint 1", State: "closed"}}}}
+	s := newCachedService(fake, "https://jira.example.com", cache)
+	if _, err := s.boardSprints(ctx, 1); err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+
+	// Sprint 2 was created and closed before the next run, sprint 3 is open
+	fake.Sprints[1] = []jira.Sprint{
+		{ID: 1, Name: "Sprint 1", State: "closed"},
+		{ID: 2, Name: "Sprint 2", State: "closed"},
+		{ID: 3, Name: "Sprint 3", State: "active"},
+	}
+	sprints, err := s.boardSprints(ctx, 1)
+	if err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+	if len(sprints) != 3 {
+		t.Errorf("got %d sprints, want 3", len(sprints))
+	}
+}
+
+func TestFileCacheKeepsSimilarKeysApart(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	keys := []string{"board-Team Board", "board-Team/Board", "board-Team_Board", "board-Team_20Board", "board-../Team"}
+	for i, key := range keys {
+		if err := cache.Set(key, i); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+
+	for i, key := range keys {
+		var got int
+		if _, ok, err := cache.Get(key, time.Hour, &got); err != nil || !ok {
+			t.Fatalf("Get(%q): %v, found %v", key, err, ok)
+		}
+		if got != i {
+			t.Errorf("Get(%q) returned the entry of %q", key, keys[got])
+		}
+	}
+}
+
+func TestBoardSprintsRefreshesWhenASprintWasClosedSince(t *testing.T) {
+	cache := NewFileCache(t.TempDir())
+	ctx := context.Background()
+
+	fake := &FakeClient{Sprints: map[int][]jira.Sprint{1: {{ID: 1, Name: "Sprint 1", State: "closed"}}}}
+	s := newCachedService(fake, "https://jira.example.com", cache)
+	if _, err := s.boardSprints(ctx, 1); err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+
+	// Sprint 2 was created and closed before the next run, and no sprint
+	// is open
+	fake.Sprints[1] = append(fake.Sprints[1], jira.Sprint{ID: 2, Name: "Sprint 2", State: "closed"})
+	sprints, err := s.boardSprints(ctx, 1)
+	if err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+	if len(sprints) != 2 {
+		t.Errorf("got %d sprints, want 2", len(sprints))
+	}
+
+	// Without changes the cache is used
+	fake.Sprints[1][0].Name = "Renamed"
+	sprints, err = s.boardSprints(ctx, 1)
+	if err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+	if sprints[0].Name != "Sprint 1" {
+		t.Errorf("got sprint %q, want the cached name", sprints[0].Name)
+	}
+}
@@ -0,0 +1,76 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// AddSprintHistoryWithContext sets the sprints of the board every issue of
+// the scope has been in and lists the issues carried into the sprint from
+// earlier ones. Earlier sprints are those that started before this one; an
+// issue was in one if the sprint lists it or its Sprint field history
+// mentions it. If some earlier sprints cannot be fetched, the issues are
+// annotated from the others and the errors are returned.
+func (s *JiraService) AddSprintHistoryWithContext(ctx context.Context, boardName string, scope *SprintScope) error {
+	board, err := s.GetBoardWithContext(ctx, boardName)
+	if err != nil {
+		return err
+	}
+	log.Printf("Found board '%s' with ID %d", boardName, board.ID)
+
+	earlier := func(sprint jira.Sprint) bool {
+		return sprint.ID != scope.Sprint.ID && sprint.StartDate != nil && sprint.StartDate.Before(scope.Start)
+	}
+	sprints, sprintsIssues, walkErr := s.walkBoardSprints(ctx, board.ID, boardName, nil, earlier)
+	if sprints == nil && walkErr != nil {
+		return walkErr
+	}
+
+	// Sprint names are listed in the order the sprints started
+	members := make(map[int]map[string]struct{}, len(sprints))
+	for i, sprint := range sprints {
+		keys := make(map[string]struct{}, len(sprintsIssues[i]))
+		for _, issue := range sprintsIssues[i] {
+			keys[issue.Key] = struct{}{}
+		}
+		members[sprint.ID] = keys
+	}
+	sort.SliceStable(sprints, func(i, j int) bool {
+		return sprints[i].StartDate.Before(*sprints[j].StartDate)
+	})
+
+	history := func(key string) []string {
+		var names []string
+		for _, sprint := range sprints {
+			_, listed := members[sprint.ID][key]
+			if listed || hasSprint(scope.changelogSprints[key], sprint.ID) {
+				names = append(names, sprint.Name)
+			}
+		}
+		return append(names, scope.Sprint.Name)
+	}
+
+	for _, group := range []*[]Issue{&scope.Committed, &scope.Added, &scope.Removed, &scope.Initial, &scope.CarriedOver} {
+		for i := range *group {
+			(*group)[i].Sprints = history((*group)[i].Key)
+		}
+	}
+
+	scope.CarriedIn = nil
+	for _, group := range [][]Issue{scope.Committed, scope.Added} {
+		for _, issue := range group {
+			if len(issue.Sprints) > 1 {
+				scope.CarriedIn = append(scope.CarriedIn, issue)
+			}
+		}
+	}
+
+	if walkErr != nil {
+		return fmt.Errorf("failed to get some earlier sprints: %w", walkErr)
+	}
+	return nil
+}
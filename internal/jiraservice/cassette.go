@@ -0,0 +1,205 @@
+package jiraservice
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CassetteMode selects whether Jira traffic is recorded to or replayed from
+// a cassette directory
+type CassetteMode string
+
+const (
+	// CassetteOff talks to Jira without recording anything
+	CassetteOff CassetteMode = ""
+	// CassetteRecord talks to Jira and saves every exchange
+	CassetteRecord CassetteMode = "record"
+	// CassetteReplay answers every request from the saved exchanges and
+	// never touches the network
+	CassetteReplay CassetteMode = "replay"
+)
+
+// ParseCassetteMode validates a cassette mode read from config
+func ParseCassetteMode(s string) (CassetteMode, error) {
+	switch mode := CassetteMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case CassetteOff, CassetteRecord, CassetteReplay:
+		return mode, nil
+	default:
+		return CassetteOff, fmt.Errorf("invalid cassette mode '%s' (use record or replay)", s)
+	}
+}
+
+// cassetteEntry is one recorded request/response pair as stored on disk
+type cassetteEntry struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// cassetteTransport records or replays HTTP exchanges. Each exchange is a
+// JSON file named after a hash of the request, so identical runs map to the
+// same files. Repeated identical requests are numbered in order and replayed
+// in the same order.
+type cassetteTransport struct {
+	dir  string
+	mode CassetteMode
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+func newCassetteTransport(dir string, mode CassetteMode, next http.RoundTripper) *cassetteTransport {
+	return &cassetteTransport{
+		dir:  dir,
+		mode: mode,
+		next: next,
+		seen: make(map[string]int),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	path := filepath.Join(t.dir, t.entryName(req, body))
+
+	if t.mode == CassetteReplay {
+		return t.replay(req, path)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.record(req, body, resp, path); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// entryName derives the cassette file name of a request. Only the method,
+// path, query and body take part, so credentials and the host never end up
+// in the key and a cassette can be replayed against any base URL.
+func (t *cassetteTransport) entryName(req *http.Request, body []byte) string {
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s\n", req.Method, req.URL.Path)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, strings.Join(query[k], ","))
+	}
+	h.Write(body)
+	key := hex.EncodeToString(h.Sum(nil))[:16]
+
+	t.mu.Lock()
+	n := t.seen[key]
+	t.seen[key] = n + 1
+	t.mu.Unlock()
+
+	return fmt.Sprintf("%s-%03d.json", key, n)
+}
+
+// record saves the exchange and rewinds the response body for the caller
+func (t *cassetteTransport) record(req *http.Request, body []byte, resp *http.Response, path string) error {
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	// Session cookies must not end up in a cassette that may be shared
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	entry := cassetteEntry{
+		Request: cassetteRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Body:   string(body),
+		},
+		Response: cassetteResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(respBody),
+		},
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to record %s %s: %w", req.Method, req.URL.RequestURI(), err)
+	}
+	return nil
+}
+
+// replay answers the request from the cassette
+func (t *cassetteTransport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no recorded response for %s %s in %s", req.Method, req.URL.RequestURI(), t.dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cassetteEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.StatusCode, http.StatusText(entry.Response.StatusCode)),
+		StatusCode:    entry.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Response.Header,
+		Body:          io.NopCloser(strings.NewReader(entry.Response.Body)),
+		ContentLength: int64(len(entry.Response.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package jiraservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// JiraClient is the part of the Jira API that JiraService depends on.
+// Every listing call returns a single page; JiraService does the paging.
+type JiraClient interface {
+	// GetBoards returns one page of agile boards matching the options
+	GetBoards(ctx context.Context, opts *jira.BoardListOptions) (*jira.BoardsList, error)
+	// GetSprints returns one page of sprints of the board
+	GetSprints(ctx context.Context, boardID int, opts *jira.GetAllSprintsOptions) (*jira.SprintsList, error)
+	// SearchIssues returns one page of issues matching the JQL query
+	SearchIssues(ctx context.Context, jql string, req PageRequest) (*IssuePage, error)
+	// GetSprintIssues returns one page of issues in the sprint
+	GetSprintIssues(ctx context.Context, sprintID int, req PageRequest) (*IssuePage, error)
+	// GetIssueChangelog returns the complete changelog of the issue
+	GetIssueChangelog(ctx context.Context, issueKey string) (*jira.Changelog, error)
+	// GetStatuses returns every workflow status with its category
+	GetStatuses(ctx context.Context) ([]jira.Status, error)
+	// GetFilter returns the saved filter with the ID
+	GetFilter(ctx context.Context, filterID int) (*jira.Filter, error)
+	// SearchFilters returns one page of saved filters matching the options
+	SearchFilters(ctx context.Context, opts *jira.FilterSearchOptions) (*jira.FiltersList, error)
+}
+
+// ErrInvalidQuery is returned by searches Jira rejects as malformed, e.g.
+// JQL functions or fields the instance does not support
+var ErrInvalidQuery = errors.New("invalid JQL query")
+
+// PageRequest selects a page of issues. Pages are addressed either by
+// StartAt or, on endpoints that use cursors, by NextPageToken.
+type PageRequest struct {
+	StartAt       int
+	NextPageToken string
+	MaxResults    int
+	Expand        string
+	Fields        []string
+	// JQL narrows down the issues of endpoints that list issues of
+	// something else, e.g. a sprint
+	JQL string
+	// ValidateQuery "warn" makes the classic search endpoint return results
+	// with warnings instead of failing, e.g. on unknown keys
+	ValidateQuery string
+}
+
+// IssuePage is one page of issues together with the information needed to
+// request the next one
+type IssuePage struct {
+	Issues        []jira.Issue
+	StartAt       int
+	Total         int
+	NextPageToken string
+	IsLast        bool
+}
+
+// httpClient implements JiraClient on top of the go-jira REST client
+type httpClient struct {
+	client *jira.Client
+
+	// tokenSearch is set once Jira rejects the classic search endpoint
+	tokenSearch atomic.Bool
+}
+
+// sprintIssuesPage is one page of the agile sprint issues endpoint. go-jira's
+// GetIssuesForSprint drops the paging fields, so the page is decoded here.
+type sprintIssuesPage struct {
+	StartAt    int          `json:"startAt"`
+	MaxResults int          `json:"maxResults"`
+	Total      int          `json:"total"`
+	Issues     []jira.Issue `json:"issues"`
+}
+
+// NewHTTPClient wraps a go-jira client as a JiraClient
+func NewHTTPClient(client *jira.Client) JiraClient {
+	return &httpClient{client: client}
+}
+
+func (c *httpClient) GetBoards(ctx context.Context, opts *jira.BoardListOptions) (*jira.BoardsList, error) {
+	boards, _, err := c.client.Board.GetAllBoardsWithContext(ctx, opts)
+	return boards, err
+}
+
+func (c *httpClient) GetSprints(ctx context.Context, boardID int, opts *jira.GetAllSprintsOptions) (*jira.SprintsList, error) {
+	sprints, _, err := c.client.Board.GetAllSprintsWithOptionsWithContext(ctx, boardID, opts)
+	return sprints, err
+}
+
+// SearchIssues uses the classic startAt based search endpoint. Instances
+// that have retired it (HTTP 410) are switched over to the nextPageToken
+// based /search/jql endpoint for the rest of the session.
+func (c *httpClient) SearchIssues(ctx context.Context, jql string, req PageRequest) (*IssuePage, error) {
+	if req.NextPageToken != "" || c.tokenSearch.Load() {
+		return c.searchByToken(ctx, jql, req)
+	}
+
+	issues, resp, err := c.client.Issue.SearchWithContext(ctx, jql, &jira.SearchOptions{
+		StartAt:       req.StartAt,
+		MaxResults:    req.MaxResults,
+		Expand:        req.Expand,
+		Fields:        req.Fields,
+		ValidateQuery: req.ValidateQuery,
+	})
+	if err != nil {
+		if req.StartAt == 0 && resp != nil && resp.Response != nil && resp.StatusCode == http.StatusGone {
+			c.tokenSearch.Store(true)
+			return c.searchByToken(ctx, jql, req)
+		}
+		return nil, searchError(resp, err)
+	}
+
+	return &IssuePage{
+		Issues:  issues,
+		StartAt: resp.StartAt,
+		Total:   resp.Total,
+		IsLast:  resp.StartAt+len(issues) >= resp.Total,
+	}, nil
+}
+
+// searchError marks searches rejected with HTTP 400 as invalid queries
+func searchError(resp *jira.Response, err error) error {
+	if resp != nil && resp.Response != nil && resp.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w: %w", ErrInvalidQuery, err)
+	}
+	return err
+}
+
+// searchByToken requests a page from /rest/api/2/search/jql
+func (c *httpClient) searchByToken(ctx context.Context, jql string, req PageRequest) (*IssuePage, error) {
+	// The token endpoint only returns issue IDs unless fields are requested
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = []string{"*navigable"}
+	}
+
+	issues, resp, err := c.client.Issue.SearchV2JQLWithContext(ctx, jql, &jira.SearchOptionsV2{
+		NextPageToken: req.NextPageToken,
+		MaxResults:    req.MaxResults,
+		Fields:        fields,
+		Expand:        req.Expand,
+	})
+	if err != nil {
+		return nil, searchError(resp, err)
+	}
+
+	return &IssuePage{
+		Issues:        issues,
+		NextPageToken: resp.NextPageToken,
+		IsLast:        resp.IsLast || resp.NextPageToken == "",
+	}, nil
+}
+
+func (c *httpClient) GetSprintIssues(ctx context.Context, sprintID int, req PageRequest) (*IssuePage, error) {
+	endpoint := fmt.Sprintf("rest/agile/1.0/sprint/%d/issue?startAt=%d&maxResults=%d", sprintID, req.StartAt, req.MaxResults)
+	if req.Expand != "" {
+		endpoint += "&expand=" + req.Expand
+	}
+	if req.JQL != "" {
+		endpoint += "&jql=" + url.QueryEscape(req.JQL)
+	}
+
+	httpReq, err := c.client.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	page := new(sprintIssuesPage)
+	resp, err := c.client.Do(httpReq, page)
+	if err != nil {
+		return nil, jira.NewJiraError(resp, err)
+	}
+
+	return &IssuePage{
+		Issues:  page.Issues,
+		StartAt: page.StartAt,
+		Total:   page.Total,
+		IsLast:  page.StartAt+len(page.Issues) >= page.Total,
+	}, nil
+}
+
+func (c *httpClient) GetIssueChangelog(ctx context.Context, issueKey string) (*jira.Changelog, error) {
+	issue, _, err := c.client.Issue.GetWithContext(ctx, issueKey, &jira.GetQueryOptions{Expand: "changelog"})
+	if err != nil {
+		return nil, err
+	}
+	if issue.Changelog == nil {
+		return &jira.Changelog{}, nil
+	}
+	return issue.Changelog, nil
+}
+
+func (c *httpClient) GetStatuses(ctx context.Context) ([]jira.Status, error) {
+	statuses, _, err := c.client.Status.GetAllStatusesWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (c *httpClient) GetFilter(ctx context.Context, filterID int) (*jira.Filter, error) {
+	filter, _, err := c.client.Filter.GetWithContext(ctx, filterID)
+	if err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (c *httpClient) SearchFilters(ctx context.Context, opts *jira.FilterSearchOptions) (*jira.FiltersList, error) {
+	filters, _, err := c.client.Filter.SearchWithContext(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
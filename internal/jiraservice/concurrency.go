@@ -0,0 +1,89 @@
+package jiraservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// defaultConcurrency is the number of sprints fetched in parallel when no
+// concurrency is configured
+const defaultConcurrency = 4
+
+// loadSprintsIssues fetches the issues of every sprint with a bounded pool
+// of workers. The result holds one slice per sprint, in the order of
+// sprints, whatever order the fetches complete in. A failing sprint does
+// not stop the others; all failures are returned joined together, and the
+// slices of the failed sprints are left nil.
+func (s *JiraService) loadSprintsIssues(ctx context.Context, sprints []jira.Sprint, typeFilter map[string]struct{}) ([][]Issue, error) {
+	results := make([][]Issue, len(sprints))
+	err := s.forEachSprint(ctx, sprints, func(i int, sprint jira.Sprint) error {
+		issues, err := s.LoadIssuesFromSprintWithContext(ctx, sprint.ID, typeFilter)
+		results[i] = issues
+		return err
+	})
+	return results, err
+}
+
+// forEachSprint calls fn for every sprint with a bounded pool of workers,
+// passing the index of the sprint so results can be stored in order. A
+// failing sprint does not stop the others; all failures are returned joined
+// together.
+func (s *JiraService) forEachSprint(ctx context.Context, sprints []jira.Sprint, fn func(i int, sprint jira.Sprint) error) error {
+	errs := make([]error, len(sprints))
+
+	workers := s.concurrency
+	if workers <= 0 {
+		workers = defaultConcurrency
+	}
+	workers = min(workers, len(sprints))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sprint := sprints[i]
+				if err := fn(i, sprint); err != nil {
+					errs[i] = fmt.Errorf("sprint '%s' (ID %d): %w", sprint.Name, sprint.ID, err)
+				}
+			}
+		}()
+	}
+
+	for i := range sprints {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// dedupeIssues flattens per-sprint issue lists, keeping the first occurrence
+// of an issue that was in several sprints
+func dedupeIssues(groups [][]Issue) []Issue {
+	seen := make(map[string]struct{})
+	result := []Issue{}
+	for _, group := range groups {
+		for _, issue := range group {
+			if _, ok := seen[issue.Key]; ok {
+				continue
+			}
+			seen[issue.Key] = struct{}{}
+			result = append(result, issue)
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// numberedSprints returns sprints with the IDs 1 to n
+func numberedSprints(n int) []jira.Sprint {
+	sprints := make([]jira.Sprint, n)
+	for i := range sprints {
+		sprints[i] = jira.Sprint{ID: i + 1, Name: fmt.Sprintf("Sprint %d", i+1)}
+	}
+	return sprints
+}
+
+func TestLoadSprintsIssuesKeepsSprintOrder(t *testing.T) {
+	// Earlier sprints answer last
+	f := &fakeJiraServer{
+		sprintKeys: map[int][]string{1: {"PROJ-1"}, 2: {"PROJ-2"}, 3: {"PROJ-3"}, 4: {"PROJ-4"}},
+		delays:     map[int]time.Duration{1: 60 * time.Millisecond, 2: 40 * time.Millisecond, 3: 20 * time.Millisecond},
+	}
+	s := newTestService(t, f, WithConcurrency(4))
+
+	groups, err := s.loadSprintsIssues(context.Background(), numberedSprints(4), nil)
+	if err != nil {
+		t.Fatalf("loadSprintsIssues: %v", err)
+	}
+	var got []string
+	for _, group := range groups {
+		got = append(got, issueKeys(group)...)
+	}
+	if want := []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLoadSprintsIssuesDeduplicates(t *testing.T) {
+	f := &fakeJiraServer{sprintKeys: map[int][]string{1: {"PROJ-1", "PROJ-2"}, 2: {"PROJ-2", "PROJ-3"}}}
+	s := newTestService(t, f)
+
+	groups, err := s.loadSprintsIssues(context.Background(), numberedSprints(2), nil)
+	if err != nil {
+		t.Fatalf("loadSprintsIssues: %v", err)
+	}
+	var got []string
+	for _, issue := range dedupeIssues(groups) {
+		got = append(got, issue.Key)
+	}
+	if want := []string{"PROJ-1", "PROJ-2", "PROJ-3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLoadSprintsIssuesJoinsErrors(t *testing.T) {
+	f := &fakeJiraServer{
+		sprintKeys: map[int][]string{1: {"PROJ-1"}, 2: {"PROJ-2"}, 3: {"PROJ-3"}},
+		failing:    map[int]bool{1: true, 3: true},
+	}
+	s := newTestService(t, f)
+
+	groups, err := s.loadSprintsIssues(context.Background(), numberedSprints(3), nil)
+	if err == nil {
+		t.Fatal("got no error, want the failures of sprints 1 and 3")
+	}
+	for _, want := range []string{"(ID 1)", "(ID 3)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention sprint %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "(ID 2)") {
+		t.Errorf("error %q mentions the sprint that succeeded", err)
+	}
+	if groups[0] != nil || groups[2] != nil || len(groups[1]) != 1 {
+		t.Errorf("got %d, %d and %d issues, want only those of sprint 2", len(groups[0]), len(groups[1]), len(groups[2]))
+	}
+}
+
+func TestLoadSprintsIssuesBoundsConcurrency(t *testing.T) {
+	const limit = 2
+	f := &fakeJiraServer{sprintKeys: make(map[int][]string), delays: make(map[int]time.Duration)}
+	for id := 1; id <= 8; id++ {
+		f.sprintKeys[id] = []string{"PROJ-1"}
+		f.delays[id] = 20 * time.Millisecond
+	}
+	s := newTestService(t, f, WithConcurrency(limit))
+
+	if _, err := s.loadSprintsIssues(context.Background(), numberedSprints(8), nil); err != nil {
+		t.Fatalf("loadSprintsIssues: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.maxInFlight > limit {
+		t.Errorf("got %d requests in flight, want at most %d", f.maxInFlight, limit)
+	}
+	if f.maxInFlight < limit {
+		t.Errorf("got at most %d requests in flight, want the sprints fetched %d at a time", f.maxInFlight, limit)
+	}
+}
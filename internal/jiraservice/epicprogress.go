@@ -0,0 +1,177 @@
+package jiraservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go-word-create/internal/jql"
+)
+
+// EpicScope selects the epics of a progress report. Epic keys win over the
+// filter, the filter over the board and the board over the project.
+type EpicScope struct {
+	ProjectKey string
+	// BoardName selects the epics of the issues in the board's sprints
+	BoardName string
+	// Filter selects the epics of the filter's issues
+	Filter   *Filter
+	EpicKeys []string
+}
+
+// EpicProgress is an epic together with its child issues
+type EpicProgress struct {
+	Key  string
+	Name string
+	URL  string
+	// Children are read with their changelog, so their flow is known
+	Children []Issue
+}
+
+// GetEpicProgressWithContext returns the epics of the scope, ordered by key,
+// with their child issues of the given types, or of any type when none are
+// given. Children are linked through the configured epic link field or
+// their parent, as getEpicKey reads them.
+func (s *JiraService) GetEpicProgressWithContext(ctx context.Context, scope EpicScope, issuesTypes []string) ([]EpicProgress, error) {
+	keys, names, err := s.epicScopeKeys(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	resolved, err := s.resolveEpicNames(ctx, keys)
+	if err != nil {
+		log.Printf("warning: failed to resolve epics: %v", err)
+		resolved = make(map[string]string)
+	}
+	for key, name := range names {
+		resolved[key] = name
+	}
+
+	epics := make(map[string]*EpicProgress, len(keys))
+	progress := make([]EpicProgress, len(keys))
+	for i, key := range keys {
+		progress[i] = EpicProgress{Key: key, Name: lookupEpicName(key, resolved), URL: fmt.Sprintf("%s/browse/%s", s.url, key)}
+		epics[key] = &progress[i]
+	}
+
+	typeFilter := createFilterMap(issuesTypes)
+	for start := 0; start < len(keys); start += epicKeysPerQuery {
+		chunk := keys[start:min(start+epicKeysPerQuery, len(keys))]
+
+		query := jql.Query{
+			Where: jql.And(s.epicChildrenClause(chunk), jql.In("issuetype", jql.Strings(issuesTypes)...), s.extraJQL),
+			Order: []jql.Order{{Field: "key"}},
+		}
+		children, err := s.searchAll(ctx, query.String(), PageRequest{Expand: "changelog"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search epic issues: %w", err)
+		}
+
+		for _, child := range filterByType(children, typeFilter) {
+			epic, ok := epics[getEpicKey(child, s.epicField)]
+			if !ok {
+				continue
+			}
+			epic.Children = append(epic.Children, s.newIssue(child, resolved))
+		}
+	}
+
+	return progress, nil
+}
+
+// epicScopeKeys returns the sorted keys of the epics in scope, and their
+// names when the scope already knows them
+func (s *JiraService) epicScopeKeys(ctx context.Context, scope EpicScope) ([]string, map[string]string, error) {
+	seen := make(map[string]struct{})
+	var keys []string
+	add := func(key string) {
+		key = strings.ToUpper(strings.TrimSpace(key))
+		if _, ok := seen[key]; key != "" && !ok {
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+
+	var names map[string]string
+	switch {
+	case len(scope.EpicKeys) > 0:
+		for _, key := range scope.EpicKeys {
+			add(key)
+		}
+
+	case scope.Filter != nil:
+		clause, err := scope.Filter.Clause()
+		if err != nil {
+			return nil, nil, err
+		}
+		fields := []string{"issuetype", "parent"}
+		if s.epicField != "" && s.epicField != parentEpicField {
+			fields = append(fields, s.epicField)
+		}
+		issues, err := s.searchAll(ctx, jql.And(clause, s.extraJQL).String(), PageRequest{Fields: fields})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get filter issues: %w", err)
+		}
+		for _, issue := range issues {
+			add(getEpicKey(issue, s.epicField))
+		}
+
+	case scope.BoardName != "":
+		board, err := s.GetBoardWithContext(ctx, scope.BoardName)
+		if err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Found board '%s' with ID %d", scope.BoardName, board.ID)
+
+		_, sprintsIssues, err := s.walkBoardSprints(ctx, board.ID, scope.BoardName, nil, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, issue := range dedupeIssues(sprintsIssues) {
+			add(issue.EpicKey)
+		}
+
+	case scope.ProjectKey != "":
+		epics, err := s.LoadEpicsWithContext(ctx, scope.ProjectKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		for key := range epics {
+			add(key)
+		}
+		names = epics
+
+	default:
+		return nil, nil, errors.New("no project, board, filter or epics to report on")
+	}
+
+	sort.Strings(keys)
+	log.Printf("Found %d epics", len(keys))
+	return keys, names, nil
+}
+
+// epicChildrenClause returns the JQL matching the issues of the epics,
+// through the epic link field and the parent
+func (s *JiraService) epicChildrenClause(keys []string) jql.Clause {
+	values := jql.Strings(keys)
+	parent := jql.In("parent", values...)
+	if s.epicField == "" || s.epicField == parentEpicField {
+		return parent
+	}
+	return jql.Or(jql.In(jqlFieldName(s.epicField), values...), parent)
+}
+
+// jqlFieldName returns how JQL refers to a field by ID, e.g. cf[10014] for
+// customfield_10014
+func jqlFieldName(id string) string {
+	if n, ok := strings.CutPrefix(id, "customfield_"); ok {
+		return "cf[" + n + "]"
+	}
+	return id
+}
@@ -0,0 +1,171 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// epicKeysPerQuery is the number of keys per "key in (...)" query, well
+// below Jira's JQL length limits
+const epicKeysPerQuery = 100
+
+// parentEpicField selects the parent field as the only epic source
+const parentEpicField = "parent"
+
+// getEpicKey returns the key of the epic the issue belongs to. The
+// configured epic link field (company-managed projects) wins; otherwise
+// the parent of a standard issue is its epic (team-managed projects and
+// Jira Cloud's unified hierarchy). Sub-task parents are stories, not epics.
+func getEpicKey(issue jira.Issue, epicFieldName string) string {
+	if issue.Fields == nil {
+		return ""
+	}
+
+	if epicFieldName != "" && epicFieldName != parentEpicField {
+		if v, ok := issue.Fields.Unknowns[epicFieldName]; ok {
+			switch t := v.(type) {
+			case string:
+				if t != "" {
+					return t
+				}
+			case map[string]interface{}:
+				if k, ok := t["key"].(string); ok && k != "" {
+					return k
+				} else if v2, ok := t["value"].(string); ok && v2 != "" {
+					return v2
+				}
+			}
+		}
+	}
+
+	if issue.Fields.Parent != nil && !issue.Fields.Type.Subtask {
+		return issue.Fields.Parent.Key
+	}
+	return ""
+}
+
+// collectEpicKeys returns the distinct epic keys referenced by the issues
+func collectEpicKeys(issues []jira.Issue, epicFieldName string) []string {
+	seen := make(map[string]struct{})
+	var keys []string
+	for _, issue := range issues {
+		key := getEpicKey(issue, epicFieldName)
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveEpicNames maps epic keys to epic summaries, looking up only the
+// given keys, in any project. Names already resolved in this session or
+// cached on disk are reused; cached names of epics updated since they were
+// cached are fetched again. Keys that cannot be resolved (deleted epics,
+// no permission) are left out, so callers fall back to the key.
+func (s *JiraService) resolveEpicNames(ctx context.Context, keys []string) (map[string]string, error) {
+	names := make(map[string]string)
+
+	var missing, cachedKeys []string
+	var oldest time.Time
+
+	s.epicMu.Lock()
+	for _, key := range keys {
+		if name, ok := s.epicNames[key]; ok {
+			names[key] = name
+			continue
+		}
+
+		var name string
+		if storedAt, ok := s.cacheGet("epic-"+key, s.cacheTTL.Epics, &name); ok {
+			names[key] = name
+			cachedKeys = append(cachedKeys, key)
+			if oldest.IsZero() || storedAt.Before(oldest) {
+				oldest = storedAt
+			}
+			continue
+		}
+		missing = append(missing, key)
+	}
+	s.epicMu.Unlock()
+
+	// Epics renamed since they were cached are fetched again
+	if len(cachedKeys) > 0 {
+		updated, err := s.epicsUpdatedSince(ctx, cachedKeys, oldest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check epics for updates: %w", err)
+		}
+		missing = append(missing, updated...)
+	}
+
+	for start := 0; start < len(missing); start += epicKeysPerQuery {
+		chunk := missing[start:min(start+epicKeysPerQuery, len(missing))]
+
+		query := jql.In("key", jql.Strings(chunk)...)
+		// Unknown keys only produce warnings instead of failing the query
+		epics, err := s.searchAll(ctx, query.String(), PageRequest{Fields: []string{"summary"}, ValidateQuery: "warn"})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Printf("warning: failed to resolve epic names for %s: %v", strings.Join(chunk, ", "), err)
+			continue
+		}
+
+		for _, epic := range epics {
+			names[epic.Key] = epic.Fields.Summary
+			s.cacheSet("epic-"+epic.Key, epic.Fields.Summary)
+		}
+	}
+
+	s.epicMu.Lock()
+	for key, name := range names {
+		s.epicNames[key] = name
+	}
+	s.epicMu.Unlock()
+
+	return names, nil
+}
+
+// epicsUpdatedSince returns the keys of the given epics updated after the
+// given time. The window is relative ("-Nm") because absolute JQL dates
+// are read in the Jira user's time zone.
+func (s *JiraService) epicsUpdatedSince(ctx context.Context, keys []string, since time.Time) ([]string, error) {
+	minutes := int(time.Since(since).Minutes()) + 1
+
+	var updated []string
+	for start := 0; start < len(keys); start += epicKeysPerQuery {
+		chunk := keys[start:min(start+epicKeysPerQuery, len(keys))]
+
+		query := jql.And(jql.In("key", jql.Strings(chunk)...), jql.Compare("updated", ">=", jql.MinutesAgo(minutes)))
+		err := s.searchIssues(ctx, query.String(), PageRequest{Fields: []string{"key"}, ValidateQuery: "warn"}, func(issue jira.Issue) error {
+			updated = append(updated, issue.Key)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return updated, nil
+}
+
+// lookupEpicName returns the epic's name, or its key when the name is unknown
+func lookupEpicName(epicKey string, epicNames map[string]string) string {
+	if name, found := epicNames[epicKey]; found {
+		return name
+	}
+	return epicKey
+}
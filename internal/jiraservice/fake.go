@@ -0,0 +1,460 @@
+package jiraservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// FakeClient is an in-memory JiraClient for running reports without a Jira
+// instance. Issues are stored in the raw Jira REST format so fixtures can be
+// captured straight from the API. Calls fail once their context is done.
+//
+// Searches understand only the clauses JiraService relies on for narrowing
+// results (project, issuetype, key and parent, with = or in); any other
+// clause is treated as matching and left to the client-side filtering.
+type FakeClient struct {
+	Boards []jira.Board
+	// Sprints holds the sprints of each board, by board ID
+	Sprints map[int][]jira.Sprint
+	// SprintIssues holds the issue keys of each sprint, by sprint ID
+	SprintIssues map[int][]string
+	Issues       []jira.Issue
+	Statuses     []jira.Status
+	Filters      []jira.Filter
+	// PageSize caps the results per page, like Jira does, so callers have
+	// to paginate. Zero means no cap.
+	PageSize int
+}
+
+// Fixture file names read by LoadFakeClient
+const (
+	fixtureBoards       = "boards.json"
+	fixtureSprints      = "sprints.json"
+	fixtureSprintIssues = "sprint-issues.json"
+	fixtureIssues       = "issues.json"
+	fixtureStatuses     = "statuses.json"
+	fixtureFilters      = "filters.json"
+)
+
+// LoadFakeClient builds a FakeClient from the JSON fixtures in dir:
+//
+//	boards.json         []Board as returned by /rest/agile/1.0/board
+//	sprints.json        {"<boardId>": [Sprint, ...]}
+//	sprint-issues.json  {"<sprintId>": ["KEY-1", ...]}
+//	issues.json         []Issue as returned by /rest/api/2/search?expand=changelog
+//	statuses.json       []Status as returned by /rest/api/2/status
+//	filters.json        []Filter as returned by /rest/api/2/filter/{id}
+//
+// Missing files are treated as empty.
+func LoadFakeClient(dir string) (*FakeClient, error) {
+	fake := &FakeClient{
+		Sprints:      make(map[int][]jira.Sprint),
+		SprintIssues: make(map[int][]string),
+		PageSize:     50,
+	}
+
+	if err := readFixture(dir, fixtureBoards, &fake.Boards); err != nil {
+		return nil, err
+	}
+
+	sprints := make(map[string][]jira.Sprint)
+	if err := readFixture(dir, fixtureSprints, &sprints); err != nil {
+		return nil, err
+	}
+	for id, list := range sprints {
+		boardID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid board ID '%s' in %s", id, fixtureSprints)
+		}
+		fake.Sprints[boardID] = list
+	}
+
+	sprintIssues := make(map[string][]string)
+	if err := readFixture(dir, fixtureSprintIssues, &sprintIssues); err != nil {
+		return nil, err
+	}
+	for id, keys := range sprintIssues {
+		sprintID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sprint ID '%s' in %s", id, fixtureSprintIssues)
+		}
+		fake.SprintIssues[sprintID] = keys
+	}
+
+	if err := readFixture(dir, fixtureIssues, &fake.Issues); err != nil {
+		return nil, err
+	}
+
+	if err := readFixture(dir, fixtureStatuses, &fake.Statuses); err != nil {
+		return nil, err
+	}
+
+	if err := readFixture(dir, fixtureFilters, &fake.Filters); err != nil {
+		return nil, err
+	}
+
+	return fake, nil
+}
+
+// readFixture decodes a JSON fixture file into v, ignoring missing files
+func readFixture(dir, name string, v interface{}) error {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read fixture %s: %w", name, err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse fixture %s: %w", name, err)
+	}
+	return nil
+}
+
+func (f *FakeClient) GetBoards(ctx context.Context, opts *jira.BoardListOptions) (*jira.BoardsList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var matched []jira.Board
+	for _, board := range f.Boards {
+		// Jira matches board names partially
+		if opts != nil && opts.Name != "" && !strings.Contains(board.Name, opts.Name) {
+			continue
+		}
+		matched = append(matched, board)
+	}
+
+	startAt, end := 0, len(matched)
+	if opts != nil {
+		startAt, end = f.pageBounds(opts.StartAt, opts.MaxResults, len(matched))
+	}
+	return &jira.BoardsList{
+		StartAt:    startAt,
+		MaxResults: end - startAt,
+		Total:      len(matched),
+		IsLast:     end >= len(matched),
+		Values:     matched[startAt:end],
+	}, nil
+}
+
+func (f *FakeClient) GetSprints(ctx context.Context, boardID int, opts *jira.GetAllSprintsOptions) (*jira.SprintsList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var sprints []jira.Sprint
+	for _, sprint := range f.Sprints[boardID] {
+		if opts != nil && opts.State != "" && !containsFold(strings.Split(opts.State, ","), sprint.State) {
+			continue
+		}
+		sprints = append(sprints, sprint)
+	}
+
+	startAt, end := 0, len(sprints)
+	if opts != nil {
+		startAt, end = f.pageBounds(opts.StartAt, opts.MaxResults, len(sprints))
+	}
+	return &jira.SprintsList{
+		StartAt:    startAt,
+		MaxResults: end - startAt,
+		Total:      len(sprints),
+		IsLast:     end >= len(sprints),
+		Values:     sprints[startAt:end],
+	}, nil
+}
+
+func (f *FakeClient) SearchIssues(ctx context.Context, jql string, req PageRequest) (*IssuePage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	clauses := parseFakeJQL(jql)
+
+	var matched []jira.Issue
+	for _, issue := range f.Issues {
+		if clauses.match(issue) {
+			matched = append(matched, issue)
+		}
+	}
+	return f.page(matched, req), nil
+}
+
+func (f *FakeClient) GetSprintIssues(ctx context.Context, sprintID int, req PageRequest) (*IssuePage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	clauses := parseFakeJQL(req.JQL)
+
+	var issues []jira.Issue
+	for _, key := range f.SprintIssues[sprintID] {
+		issue, ok := f.issue(key)
+		if !ok {
+			return nil, fmt.Errorf("sprint %d references unknown issue %s", sprintID, key)
+		}
+		if clauses.match(issue) {
+			issues = append(issues, issue)
+		}
+	}
+	return f.page(issues, req), nil
+}
+
+func (f *FakeClient) GetIssueChangelog(ctx context.Context, issueKey string) (*jira.Changelog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	issue, ok := f.issue(issueKey)
+	if !ok {
+		return nil, fmt.Errorf("issue %s not found", issueKey)
+	}
+	if issue.Changelog == nil {
+		return &jira.Changelog{}, nil
+	}
+	return issue.Changelog, nil
+}
+
+func (f *FakeClient) GetStatuses(ctx context.Context) ([]jira.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.Statuses, nil
+}
+
+func (f *FakeClient) GetFilter(ctx context.Context, filterID int) (*jira.Filter, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, filter := range f.Filters {
+		if filter.ID == strconv.Itoa(filterID) {
+			return &filter, nil
+		}
+	}
+	return nil, fmt.Errorf("filter %d not found", filterID)
+}
+
+func (f *FakeClient) SearchFilters(ctx context.Context, opts *jira.FilterSearchOptions) (*jira.FiltersList, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var matched []jira.FiltersListItem
+	for _, filter := range f.Filters {
+		// Jira matches filter names partially, ignoring case
+		if opts != nil && !strings.Contains(strings.ToLower(filter.Name), strings.ToLower(opts.FilterName)) {
+			continue
+		}
+		matched = append(matched, jira.FiltersListItem{ID: filter.ID, Name: filter.Name, Jql: filter.Jql})
+	}
+
+	startAt, end := 0, len(matched)
+	if opts != nil {
+		startAt, end = f.pageBounds(int(opts.StartAt), int(opts.MaxResults), len(matched))
+	}
+	return &jira.FiltersList{
+		StartAt:    startAt,
+		MaxResults: end - startAt,
+		Total:      len(matched),
+		IsLast:     end >= len(matched),
+		Values:     matched[startAt:end],
+	}, nil
+}
+
+// containsFold reports whether list holds value, ignoring case
+func containsFold(list []string, value string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// issue looks up an issue by key
+func (f *FakeClient) issue(key string) (jira.Issue, bool) {
+	for _, issue := range f.Issues {
+		if issue.Key == key {
+			return issue, true
+		}
+	}
+	return jira.Issue{}, false
+}
+
+// page slices the requested page out of issues
+func (f *FakeClient) page(issues []jira.Issue, req PageRequest) *IssuePage {
+	startAt, end := f.pageBounds(req.StartAt, req.MaxResults, len(issues))
+
+	page := make([]jira.Issue, 0, end-startAt)
+	for _, issue := range issues[startAt:end] {
+		// Changelogs are only returned when asked for, like the real API
+		if req.Expand != "changelog" {
+			issue.Changelog = nil
+		}
+		page = append(page, issue)
+	}
+
+	return &IssuePage{
+		Issues:  page,
+		StartAt: startAt,
+		Total:   len(issues),
+		IsLast:  end >= len(issues),
+	}
+}
+
+// pageBounds returns the slice bounds of a page, honouring PageSize
+func (f *FakeClient) pageBounds(startAt, maxResults, total int) (int, int) {
+	if maxResults <= 0 || (f.PageSize > 0 && maxResults > f.PageSize) {
+		maxResults = f.PageSize
+	}
+	if startAt > total {
+		startAt = total
+	}
+	end := total
+	if maxResults > 0 && startAt+maxResults < total {
+		end = startAt + maxResults
+	}
+	return startAt, end
+}
+
+// fakeClause is a single "field = value" or "field in (values)" condition
+type fakeClause struct {
+	field  string
+	values map[string]struct{}
+}
+
+type fakeClauses []fakeClause
+
+var (
+	fakeOrderBy  = regexp.MustCompile(`(?i)\s+ORDER\s+BY\s+.*$`)
+	fakeAnd      = regexp.MustCompile(`(?i)\s+AND\s+`)
+	fakeOr       = regexp.MustCompile(`(?i)\s+OR\s+`)
+	fakeEquals   = regexp.MustCompile(`(?i)^(project|issuetype|type|key|parent)\s*=\s*(.+)$`)
+	fakeInValues = regexp.MustCompile(`(?i)^(project|issuetype|type|key|parent)\s+in\s*\((.*)\)$`)
+)
+
+// parseFakeJQL extracts the top-level clauses the fake knows how to apply
+func parseFakeJQL(jql string) fakeClauses {
+	jql = fakeOrderBy.ReplaceAllString(strings.TrimSpace(jql), "")
+
+	var clauses fakeClauses
+	for _, part := range fakeSplitAnd(jql) {
+		// Alternatives are left to the client-side filtering
+		if fakeOr.MatchString(part) {
+			continue
+		}
+
+		var field string
+		var values []string
+		if m := fakeEquals.FindStringSubmatch(part); m != nil {
+			field, values = m[1], []string{m[2]}
+		} else if m := fakeInValues.FindStringSubmatch(part); m != nil {
+			field, values = m[1], strings.Split(m[2], ",")
+		} else {
+			continue
+		}
+
+		clause := fakeClause{field: strings.ToLower(field), values: make(map[string]struct{})}
+		if clause.field == "type" {
+			clause.field = "issuetype"
+		}
+		for _, v := range values {
+			clause.values[strings.ToLower(strings.Trim(strings.TrimSpace(v), `"'`))] = struct{}{}
+		}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// fakeSplitAnd splits JQL on its top-level ANDs. Parenthesised groups such
+// as user JQL are split too when they hold no OR.
+func fakeSplitAnd(jql string) []string {
+	var parts []string
+	depth, from := 0, 0
+	for i := 0; i < len(jql); i++ {
+		switch jql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth != 0 {
+			continue
+		}
+		if m := fakeAnd.FindStringIndex(jql[i:]); m != nil && m[0] == 0 {
+			parts = append(parts, jql[from:i])
+			from = i + m[1]
+			i = from - 1
+		}
+	}
+	parts = append(parts, jql[from:])
+
+	var out []string
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if inner, ok := fakeGroup(part); ok && !fakeOr.MatchString(inner) {
+			out = append(out, fakeSplitAnd(inner)...)
+			continue
+		}
+		out = append(out, part)
+	}
+	return out
+}
+
+// fakeGroup returns the inside of a clause wrapped in a single pair of
+// parentheses
+func fakeGroup(part string) (string, bool) {
+	if !strings.HasPrefix(part, "(") || !strings.HasSuffix(part, ")") {
+		return "", false
+	}
+	depth := 0
+	for i, r := range part {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(part)-1 {
+				return "", false
+			}
+		}
+	}
+	return strings.TrimSpace(part[1 : len(part)-1]), true
+}
+
+// match reports whether the issue satisfies every clause
+func (c fakeClauses) match(issue jira.Issue) bool {
+	for _, clause := range c {
+		var value string
+		switch clause.field {
+		case "project":
+			if issue.Fields != nil {
+				value = issue.Fields.Project.Key
+			}
+		case "issuetype":
+			if issue.Fields != nil {
+				value = issue.Fields.Type.Name
+			}
+		case "key":
+			value = issue.Key
+		case "parent":
+			if issue.Fields != nil && issue.Fields.Parent != nil {
+				value = issue.Fields.Parent.Key
+			}
+		}
+		if _, ok := clause.values[strings.ToLower(value)]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package jiraservice
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+const fixturesDir = "../../testdata/jira"
+
+// newFixtureService returns a service on the fixtures in testdata, with
+// pages of at most pageSize results
+func newFixtureService(t *testing.T, pageSize int) *JiraService {
+	t.Helper()
+	fake, err := LoadFakeClient(fixturesDir)
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake.PageSize = pageSize
+	return NewJiraServiceWithClient(fake, "https://jira.example.com", "customfield_14500", "customfield_10004",
+		WithLocation(time.UTC))
+}
+
+// issueKeys returns the sorted keys of the issues
+func issueKeys(issues []Issue) []string {
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestFixtureMonthIssues(t *testing.T) {
+	start := time.Date(2025, time.October, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	want := []string{"PROJ-2", "PROJ-3", "PROJ-5", "PROJ-7", "PROJ-8"}
+
+	// One issue per page makes every search paginate
+	for _, pageSize := range []int{50, 1} {
+		s := newFixtureService(t, pageSize)
+		issues, err := s.GetIssuesInProgressDuringWithContext(context.Background(), "PROJ", start, end, []string{"Bug", "Story", "Task"})
+		if err != nil {
+			t.Fatalf("page size %d: %v", pageSize, err)
+		}
+		if got := issueKeys(issues); !reflect.DeepEqual(got, want) {
+			t.Errorf("page size %d: got %v, want %v", pageSize, got, want)
+		}
+	}
+}
+
+func TestFixtureSprintScope(t *testing.T) {
+	for _, pageSize := range []int{50, 1} {
+		s := newFixtureService(t, pageSize)
+		ctx := context.Background()
+
+		sprint, err := s.FindSprintWithContext(ctx, "Team Board", "Sprint 16")
+		if err != nil {
+			t.Fatalf("page size %d: %v", pageSize, err)
+		}
+		scope, err := s.GetSprintScopeWithContext(ctx, "PROJ", sprint, []string{"Bug", "Feature", "Task"})
+		if err != nil {
+			t.Fatalf("page size %d: %v", pageSize, err)
+		}
+
+		groups := map[string]struct {
+			got  []Issue
+			want []string
+		}{
+			"committed": {scope.Committed, []string{"PROJ-3", "PROJ-6", "PROJ-7"}},
+			"added":     {scope.Added, []string{"PROJ-4", "PROJ-8"}},
+			"removed":   {scope.Removed, []string{"PROJ-9"}},
+		}
+		for name, group := range groups {
+			if got := issueKeys(group.got); !reflect.DeepEqual(got, group.want) {
+				t.Errorf("page size %d: %s got %v, want %v", pageSize, name, got, group.want)
+			}
+		}
+	}
+}
+
+func TestFakeClientCapsPages(t *testing.T) {
+	fake, err := LoadFakeClient(fixturesDir)
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake.PageSize = 2
+
+	page, err := fake.SearchIssues(context.Background(), "project = PROJ", PageRequest{MaxResults: 100})
+	if err != nil {
+		t.Fatalf("SearchIssues: %v", err)
+	}
+	if len(page.Issues) != 2 || page.IsLast || page.Total <= 2 {
+		t.Errorf("got %d issues of %d, last %v; want a page of 2 that is not the last", len(page.Issues), page.Total, page.IsLast)
+	}
+}
+
+func TestFakeClientNarrowsOnParent(t *testing.T) {
+	fake, err := LoadFakeClient(fixturesDir)
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+
+	page, err := fake.SearchIssues(context.Background(), `parent in ("OTHER-1")`, PageRequest{MaxResults: 100})
+	if err != nil {
+		t.Fatalf("SearchIssues: %v", err)
+	}
+	if len(page.Issues) != 1 || page.Issues[0].Key != "PROJ-8" {
+		t.Errorf("got %d issues, want only PROJ-8", len(page.Issues))
+	}
+}
+
+func TestFixtureEpicProgressOfFilter(t *testing.T) {
+	s := newFixtureService(t, 50)
+	ctx := context.Background()
+
+	filter, err := s.FindFilterWithContext(ctx, "Reporting tasks")
+	if err != nil {
+		t.Fatalf("FindFilter: %v", err)
+	}
+	epics, err := s.GetEpicProgressWithContext(ctx, EpicScope{Filter: filter}, nil)
+	if err != nil {
+		t.Fatalf("GetEpicProgress: %v", err)
+	}
+
+	var keys []string
+	for _, epic := range epics {
+		keys = append(keys, epic.Key)
+	}
+	if want := []string{"OTHER-1", "PROJ-1"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("got epics %v, want %v", keys, want)
+	}
+}
@@ -0,0 +1,281 @@
+package jiraservice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// FieldSource is what an extractor reads an Issue attribute from
+type FieldSource struct {
+	// Raw is the issue as returned by Jira
+	Raw jira.Issue
+	// EpicNames maps the keys of the epics referenced by the issues being
+	// mapped to their names
+	EpicNames map[string]string
+	// BaseURL is the Jira base URL, for building links
+	BaseURL string
+	// StatusCategory classifies statuses by name; when nil the category
+	// Jira reports for the current status is used
+	StatusCategory func(status string) StatusCategory
+	// Location is the time zone dates are reported in; nil keeps the
+	// offsets Jira returned
+	Location *time.Location
+}
+
+// Field returns the raw value of a field by ID, e.g. "customfield_10004",
+// and whether the issue has it
+func (src FieldSource) Field(id string) (interface{}, bool) {
+	if src.Raw.Fields == nil {
+		return nil, false
+	}
+	v, ok := src.Raw.Fields.Unknowns[id]
+	return v, ok && v != nil
+}
+
+// localTime converts a Jira timestamp to the reporting time zone
+func (src FieldSource) localTime(t jira.Time) time.Time {
+	tt := time.Time(t)
+	if src.Location == nil || tt.IsZero() {
+		return tt
+	}
+	return tt.In(src.Location)
+}
+
+// statusCategory returns the category of the status
+func (src FieldSource) statusCategory(status string) StatusCategory {
+	if src.StatusCategory != nil {
+		return src.StatusCategory(status)
+	}
+	if src.Raw.Fields != nil && src.Raw.Fields.Status != nil && src.Raw.Fields.Status.Name == status {
+		if key := StatusCategory(src.Raw.Fields.Status.StatusCategory.Key); key != "" {
+			return key
+		}
+	}
+	if category, ok := defaultStatusCategories[strings.ToLower(status)]; ok {
+		return category
+	}
+	return StatusToDo
+}
+
+// FieldExtractor sets one attribute of dst from the source issue
+type FieldExtractor func(src FieldSource, dst *Issue)
+
+// CustomField maps a Jira field into Issue.Custom under a report name
+type CustomField struct {
+	// Name is the key in Issue.Custom and the report column heading
+	Name string
+	// ID is the Jira field ID, e.g. "customfield_10100"
+	ID string
+}
+
+// FieldMapper builds Issue values from raw Jira issues. Every attribute is
+// produced by a registered extractor, run in registration order.
+type FieldMapper struct {
+	names      []string
+	extractors map[string]FieldExtractor
+}
+
+// NewFieldMapper returns a mapper with the built-in extractors for the
+// standard Issue attributes, reading the epic link and story points from
+// the given fields, plus one extractor per custom field
+func NewFieldMapper(epicField, spField string, customFields []CustomField) *FieldMapper {
+	m := &FieldMapper{extractors: make(map[string]FieldExtractor)}
+
+	m.Register("key", func(src FieldSource, dst *Issue) {
+		dst.Key = src.Raw.Key
+	})
+	m.Register("summary", func(src FieldSource, dst *Issue) {
+		dst.Summary = src.Raw.Fields.Summary
+	})
+	m.Register("type", func(src FieldSource, dst *Issue) {
+		dst.Type = src.Raw.Fields.Type.Name
+	})
+	m.Register("status", func(src FieldSource, dst *Issue) {
+		if src.Raw.Fields.Status != nil {
+			dst.Status = src.Raw.Fields.Status.Name
+			dst.StatusCategory = src.statusCategory(dst.Status)
+		}
+	})
+	m.Register("url", func(src FieldSource, dst *Issue) {
+		dst.URL = fmt.Sprintf("%s/browse/%s", src.BaseURL, src.Raw.Key)
+	})
+	m.Register("epic", func(src FieldSource, dst *Issue) {
+		dst.EpicKey = getEpicKey(src.Raw, epicField)
+		dst.Epic = lookupEpicName(dst.EpicKey, src.EpicNames)
+	})
+	m.Register("storyPoints", func(src FieldSource, dst *Issue) {
+		if v, ok := src.Field(spField); ok {
+			dst.StoryPoints, _ = FieldNumber(v)
+		}
+	})
+	m.Register("assignee", func(src FieldSource, dst *Issue) {
+		dst.Assignee = userName(src.Raw.Fields.Assignee)
+	})
+	m.Register("reporter", func(src FieldSource, dst *Issue) {
+		dst.Reporter = userName(src.Raw.Fields.Reporter)
+	})
+	m.Register("priority", func(src FieldSource, dst *Issue) {
+		if src.Raw.Fields.Priority != nil {
+			dst.Priority = src.Raw.Fields.Priority.Name
+		}
+	})
+	m.Register("resolution", func(src FieldSource, dst *Issue) {
+		if src.Raw.Fields.Resolution != nil {
+			dst.Resolution = src.Raw.Fields.Resolution.Name
+		}
+	})
+	m.Register("labels", func(src FieldSource, dst *Issue) {
+		dst.Labels = src.Raw.Fields.Labels
+	})
+	m.Register("components", func(src FieldSource, dst *Issue) {
+		for _, c := range src.Raw.Fields.Components {
+			if c != nil {
+				dst.Components = append(dst.Components, c.Name)
+			}
+		}
+	})
+	m.Register("fixVersions", func(src FieldSource, dst *Issue) {
+		for _, v := range src.Raw.Fields.FixVersions {
+			if v != nil {
+				dst.FixVersions = append(dst.FixVersions, v.Name)
+			}
+		}
+	})
+	m.Register("dates", func(src FieldSource, dst *Issue) {
+		dst.Created = src.localTime(src.Raw.Fields.Created)
+		dst.Updated = src.localTime(src.Raw.Fields.Updated)
+		dst.Resolved = src.localTime(src.Raw.Fields.Resolutiondate)
+	})
+	m.Register("flow", func(src FieldSource, dst *Issue) {
+		if src.Raw.Changelog == nil {
+			return
+		}
+		var status string
+		if src.Raw.Fields.Status != nil {
+			status = src.Raw.Fields.Status.Name
+		}
+		created := src.localTime(src.Raw.Fields.Created)
+		dst.Timeline = BuildStatusTimeline(created, status, src.Raw.Changelog)
+		if src.Location != nil {
+			for i := range dst.Timeline {
+				dst.Timeline[i].From = dst.Timeline[i].From.In(src.Location)
+				if !dst.Timeline[i].To.IsZero() {
+					dst.Timeline[i].To = dst.Timeline[i].To.In(src.Location)
+				}
+			}
+		}
+		dst.Flow = ComputeFlowMetrics(created, dst.Timeline, src.statusCategory, time.Now())
+	})
+
+	for _, field := range customFields {
+		m.Register("custom:"+field.Name, customFieldExtractor(field))
+	}
+
+	return m
+}
+
+// Register adds an extractor under the given name, replacing any extractor
+// already registered under it
+func (m *FieldMapper) Register(name string, extract FieldExtractor) {
+	if _, ok := m.extractors[name]; !ok {
+		m.names = append(m.names, name)
+	}
+	m.extractors[name] = extract
+}
+
+// Map builds an Issue from the source by running every extractor
+func (m *FieldMapper) Map(src FieldSource) Issue {
+	var issue Issue
+	if src.Raw.Fields == nil {
+		src.Raw.Fields = &jira.IssueFields{}
+	}
+	for _, name := range m.names {
+		m.extractors[name](src, &issue)
+	}
+	return issue
+}
+
+// userName returns the display name of a user, or "" when unset
+func userName(u *jira.User) string {
+	if u == nil {
+		return ""
+	}
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Name
+}
+
+// customFieldExtractor copies a field into Issue.Custom as text
+func customFieldExtractor(field CustomField) FieldExtractor {
+	return func(src FieldSource, dst *Issue) {
+		v, ok := src.Field(field.ID)
+		if !ok {
+			return
+		}
+		if dst.Custom == nil {
+			dst.Custom = make(map[string]string)
+		}
+		dst.Custom[field.Name] = FieldString(v)
+	}
+}
+
+// FieldString renders a raw field value as text. Option and user objects
+// are shown by their value, name or display name, arrays as a comma
+// separated list and whole numbers without decimals.
+func FieldString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case bool:
+		return strconv.FormatBool(t)
+	case map[string]interface{}:
+		for _, k := range []string{"value", "name", "displayName", "key"} {
+			if s, ok := t[k]; ok && s != nil {
+				return FieldString(s)
+			}
+		}
+		return ""
+	case []interface{}:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			if s := FieldString(item); s != "" {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// FieldNumber reads a raw field value as a number. Numeric strings and
+// objects holding the number under "value" are accepted too.
+func FieldNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	case map[string]interface{}:
+		return FieldNumber(t["value"])
+	}
+	return 0, false
+}
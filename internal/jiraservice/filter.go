@@ -0,0 +1,100 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// Filter is a saved Jira search
+type Filter struct {
+	ID   int
+	Name string
+	JQL  string
+}
+
+// GetFilterWithContext returns the saved filter with the ID
+func (s *JiraService) GetFilterWithContext(ctx context.Context, filterID int) (*Filter, error) {
+	filter, err := s.client.GetFilter(ctx, filterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get filter %d: %w", filterID, err)
+	}
+	return &Filter{ID: filterID, Name: filter.Name, JQL: filter.Jql}, nil
+}
+
+// FindFilterWithContext returns the saved filter with the given ID or name.
+// Names are matched ignoring case; a name that only partially matches is
+// accepted when exactly one filter contains it.
+func (s *JiraService) FindFilterWithContext(ctx context.Context, idOrName string) (*Filter, error) {
+	idOrName = strings.TrimSpace(idOrName)
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		return s.GetFilterWithContext(ctx, id)
+	}
+
+	candidates, err := s.searchFilters(ctx, idOrName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search filters: %w", err)
+	}
+
+	var exact []Filter
+	for _, f := range candidates {
+		if strings.EqualFold(f.Name, idOrName) {
+			exact = append(exact, f)
+		}
+	}
+	if len(exact) == 0 {
+		exact = candidates
+	}
+
+	switch len(exact) {
+	case 0:
+		return nil, fmt.Errorf("filter '%s' not found", idOrName)
+	case 1:
+		return &exact[0], nil
+	}
+
+	names := make([]string, 0, len(exact))
+	for _, f := range exact {
+		names = append(names, fmt.Sprintf("'%s' (%d)", f.Name, f.ID))
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("filter '%s' is ambiguous, use its ID: %s", idOrName, strings.Join(names, ", "))
+}
+
+// searchFilters returns every saved filter whose name contains the text
+func (s *JiraService) searchFilters(ctx context.Context, name string) ([]Filter, error) {
+	var filters []Filter
+	opts := &jira.FilterSearchOptions{FilterName: name, Expand: "jql", MaxResults: 100}
+	for {
+		page, err := s.client.SearchFilters(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range page.Values {
+			id, err := strconv.Atoi(f.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter ID '%s'", f.ID)
+			}
+			filters = append(filters, Filter{ID: id, Name: f.Name, JQL: f.Jql})
+		}
+		if page.IsLast || len(page.Values) == 0 {
+			return filters, nil
+		}
+		opts.StartAt += int64(len(page.Values))
+	}
+}
+
+// Clause returns the filter's condition, without its ordering
+func (f *Filter) Clause() (jql.Clause, error) {
+	clause, err := jql.Raw(f.JQL)
+	if err != nil {
+		return jql.Clause{}, fmt.Errorf("invalid JQL in filter '%s': %w", f.Name, err)
+	}
+	return clause, nil
+}
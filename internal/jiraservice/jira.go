@@ -1,55 +1,247 @@
 package jiraservice
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
 )
 
 type JiraService struct {
-	client    *jira.Client
+	client    JiraClient
 	epicField string
 	spField   string
 	url       string
+	mapper    *FieldMapper
+
+	// statuses classifies statuses as to do, in progress or done
+	statuses *statusCategories
+	// location is the time zone of the reported dates
+	location *time.Location
+	// extraJQL narrows down the issues of every report
+	extraJQL jql.Clause
+
+	// concurrency is the number of sprints fetched in parallel
+	concurrency int
+
+	// cache keeps boards, closed sprints and epics between runs, nil if disabled
+	cache    Cache
+	cacheTTL CacheTTL
+	// cacheNamespace prefixes the cache keys with the Jira instance
+	cacheNamespace string
+
+	// epicNames holds the epic names resolved so far in this session
+	epicMu    sync.Mutex
+	epicNames map[string]string
 }
 
 type Issue struct {
-	Key         string
-	Summary     string
-	Epic        string
+	Key     string
+	Summary string
+	Epic    string
+	// EpicKey is the key of the epic, empty when the issue has none
+	EpicKey     string
 	StoryPoints float64
 	Type        string
 	Status      string
-	URL         string
+	// StatusCategory is whether Status means to do, in progress or done
+	StatusCategory StatusCategory
+	URL            string
+	Assignee       string
+	Reporter       string
+	Priority       string
+	Resolution     string
+	Labels         []string
+	Components     []string
+	FixVersions    []string
+	// Created, Updated and Resolved are zero when Jira has no value
+	Created  time.Time
+	Updated  time.Time
+	Resolved time.Time
+	// Timeline and Flow are derived from the changelog, so they are only
+	// set by the fetches that read it
+	Timeline []StatusInterval
+	Flow     FlowMetrics
+	// Custom holds the configured custom fields as text, by name
+	Custom map[string]string
+	// Sprints names the sprints the issue has been in, oldest first. Only
+	// set by AddSprintHistory.
+	Sprints []string
+}
+
+// Option customises how NewJiraService talks to Jira
+type Option func(*options)
+
+type options struct {
+	cassetteDir    string
+	cassetteMode   CassetteMode
+	retry          RetryPolicy
+	concurrency    int
+	cache          Cache
+	cacheTTL       CacheTTL
+	customFields   []CustomField
+	activeStatuses []string
+	doneStatuses   []string
+	location       *time.Location
+	extraJQL       jql.Clause
+}
+
+// WithCassette records all Jira traffic to dir, or replays it from dir
+// without touching the network, depending on mode
+func WithCassette(dir string, mode CassetteMode) Option {
+	return func(o *options) {
+		o.cassetteDir = dir
+		o.cassetteMode = mode
+	}
+}
+
+// WithRetry replaces the default retry policy. A policy with MaxRetries of
+// zero disables retrying.
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = policy
+	}
+}
+
+// WithConcurrency sets how many sprints are fetched in parallel by
+// GetAllBoardIssues and GetClosedSprintScopes
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithCache keeps boards, closed sprints and epics in cache between runs
+func WithCache(cache Cache, ttl CacheTTL) Option {
+	return func(o *options) {
+		o.cache = cache
+		o.cacheTTL = ttl
+	}
+}
+
+// WithCustomFields maps additional Jira fields into Issue.Custom
+func WithCustomFields(fields []CustomField) Option {
+	return func(o *options) {
+		o.customFields = fields
+	}
+}
+
+// WithActiveStatuses sets the statuses in which an issue counts as being
+// worked on. By default these are the statuses of Jira's In Progress
+// category.
+func WithActiveStatuses(statuses []string) Option {
+	return func(o *options) {
+		o.activeStatuses = statuses
+	}
+}
+
+// WithDoneStatuses sets the statuses in which an issue counts as done. By
+// default these are the statuses of Jira's Done category.
+func WithDoneStatuses(statuses []string) Option {
+	return func(o *options) {
+		o.doneStatuses = statuses
+	}
+}
+
+// WithLocation reports issue dates in the given time zone instead of the
+// offsets Jira returns them with
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		o.location = loc
+	}
+}
+
+// WithJQL restricts the issues of every report to those also matching the
+// clause, e.g. one given by the user. Board, sprint and epic lookups are
+// not affected.
+func WithJQL(clause jql.Clause) Option {
+	return func(o *options) {
+		o.extraJQL = clause
+	}
 }
 
-func NewJiraService(baseURL, username, password, epicField, spField string) (*JiraService, error) {
-	tp := jira.BasicAuthTransport{
+func NewJiraService(baseURL, username, password, epicField, spField string, opts ...Option) (*JiraService, error) {
+	o := &options{retry: DefaultRetryPolicy()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	tp := &jira.BasicAuthTransport{
 		Username: username,
 		Password: password,
 	}
 
-	client, err := jira.NewClient(tp.Client(), baseURL)
+	var transport http.RoundTripper = tp
+	if o.retry.MaxRetries > 0 {
+		transport = newRetryTransport(o.retry, transport)
+	}
+	// Recording sits outside the retries so a cassette holds only the final
+	// response of every call and replays without waiting
+	if o.cassetteMode != CassetteOff {
+		if o.cassetteDir == "" {
+			return nil, fmt.Errorf("cassette directory is required in %s mode", o.cassetteMode)
+		}
+		transport = newCassetteTransport(o.cassetteDir, o.cassetteMode, transport)
+	}
+
+	client, err := jira.NewClient(&http.Client{Transport: transport}, baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Jira client: %w", err)
 	}
 
+	return NewJiraServiceWithClient(NewHTTPClient(client), baseURL, epicField, spField, opts...), nil
+}
+
+// NewJiraServiceWithClient creates a service on top of an existing client,
+// e.g. a FakeClient loaded from fixtures. baseURL is only used to build
+// issue links. Options that configure the HTTP transport have no effect.
+func NewJiraServiceWithClient(client JiraClient, baseURL, epicField, spField string, opts ...Option) *JiraService {
+	o := &options{concurrency: defaultConcurrency}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	return &JiraService{
-		client:    client,
-		epicField: epicField,
-		spField:   spField,
-		url:       baseURL,
-	}, nil
+		client:         client,
+		epicField:      epicField,
+		spField:        spField,
+		url:            baseURL,
+		mapper:         NewFieldMapper(epicField, spField, o.customFields),
+		statuses:       newStatusCategories(o.activeStatuses, o.doneStatuses),
+		location:       o.location,
+		extraJQL:       o.extraJQL,
+		concurrency:    o.concurrency,
+		cache:          o.cache,
+		cacheTTL:       o.cacheTTL,
+		cacheNamespace: cacheNamespace(baseURL),
+		epicNames:      make(map[string]string),
+	}
 }
 
+// GetBoard wraps GetBoardWithContext using the background context.
 func (s *JiraService) GetBoard(boardName string) (*jira.Board, error) {
+	return s.GetBoardWithContext(context.Background(), boardName)
+}
+
+// GetBoardWithContext finds the agile board with the given name
+func (s *JiraService) GetBoardWithContext(ctx context.Context, boardName string) (*jira.Board, error) {
+	cacheKey := "board-" + boardName
+	var cached jira.Board
+	if _, ok := s.cacheGet(cacheKey, s.cacheTTL.Boards, &cached); ok {
+		return &cached, nil
+	}
 
 	// First, find the board ID
-	boards, _, err := s.client.Board.GetAllBoards(&jira.BoardListOptions{
+	boards, err := s.client.GetBoards(ctx, &jira.BoardListOptions{
 		ProjectKeyOrID: "",
 		Name:           boardName,
 	})
@@ -64,18 +256,39 @@ func (s *JiraService) GetBoard(boardName string) (*jira.Board, error) {
 	//boardID := strconv.Itoa(boards.Values[0].ID)
 	//log.Printf("Found board '%s' with ID %d", boardName, boardID)
 
+	s.cacheSet(cacheKey, boards.Values[0])
+
 	return &boards.Values[0], nil
 }
 
-// LoadEpics loads all Epic issues for the given project key and returns
-// a slice of maps with keys "key" and "value" (summary).
+// LoadEpics wraps LoadEpicsWithContext using the background context.
 func (s *JiraService) LoadEpics(projectKey string) (map[string]string, error) {
+	return s.LoadEpicsWithContext(context.Background(), projectKey)
+}
+
+// LoadEpicsWithContext loads all Epic issues for the given project key and returns
+// a slice of maps with keys "key" and "value" (summary).
+func (s *JiraService) LoadEpicsWithContext(ctx context.Context, projectKey string) (map[string]string, error) {
+	cacheKey := "epics-" + projectKey
+	var cached map[string]string
+	if storedAt, ok := s.cacheGet(cacheKey, s.cacheTTL.Epics, &cached); ok {
+		changed, err := s.projectEpicsUpdatedSince(ctx, projectKey, storedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check epics for updates: %w", err)
+		}
+		if !changed {
+			return cached, nil
+		}
+		s.cacheDelete(cacheKey)
+	}
+
 	// JQL to find epics in the project
-	jql := fmt.Sprintf("project = %s AND issuetype = Epic ORDER BY key", projectKey)
+	query := jql.Query{
+		Where: jql.And(jql.Eq("project", jql.String(projectKey)), jql.Eq("issuetype", jql.String("Epic"))),
+		Order: []jql.Order{{Field: "key"}},
+	}
 
-	// Fetch up to 1000 epics (adjust MaxResults if you expect more)
-	opts := &jira.SearchOptions{MaxResults: 1000}
-	issues, _, err := s.client.Issue.Search(jql, opts)
+	issues, err := s.searchAll(ctx, query.String(), PageRequest{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search epics: %w", err)
 	}
@@ -90,54 +303,39 @@ func (s *JiraService) LoadEpics(projectKey string) (map[string]string, error) {
 		epics[is.Key] = val
 	}
 
+	s.cacheSet(cacheKey, epics)
+
 	return epics, nil
 }
 
-func (s *JiraService) GetAllBoardIssues(projectKey, boardName string, issuesTypesFilter []string) ([]Issue, error) {
-	// First, find the board ID
-	board, err := s.GetBoard(boardName)
-	if err != nil {
-		return nil, err
-	}
-
-	boardID := strconv.Itoa(board.ID)
-	log.Printf("Found board '%s' with ID %s", boardName, boardID)
-
-	// Get all sprints for the board
-	sprints, _, err := s.client.Board.GetAllSprints(boardID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get sprints: %w", err)
-	}
-
-	log.Printf("Found %d sprints for board '%s'", len(sprints), boardName)
+// projectEpicsUpdatedSince reports whether any epic of the project was
+// updated after the given time
+func (s *JiraService) projectEpicsUpdatedSince(ctx context.Context, projectKey string, since time.Time) (bool, error) {
+	minutes := int(time.Since(since).Minutes()) + 1
+	query := jql.And(
+		jql.Eq("project", jql.String(projectKey)),
+		jql.Eq("issuetype", jql.String("Epic")),
+		jql.Compare("updated", ">=", jql.MinutesAgo(minutes)))
 
-	// Fetch epic summaries for the collected epic keys
-	epicNames, err := s.LoadEpics(projectKey)
+	page, err := s.client.SearchIssues(ctx, query.String(), PageRequest{MaxResults: 1, Fields: []string{"key"}})
 	if err != nil {
-		return nil, fmt.Errorf("failed to load epics: %w", err)
-	}
-
-	// Prepare a filter map from issuesTypes (if provided) for O(1) checks
-	typeFilter := createFilterMap(issuesTypesFilter)
-
-	allMonthsIssues := []Issue{}
-	for _, sprint := range sprints {
-		log.Printf("Sprint: ID=%d, Name=%s, State=%s", sprint.ID, sprint.Name, sprint.State)
-
-		sprintIssues, err := s.LoadIssuesFromSprint(sprint.ID, epicNames, typeFilter)
-		if err != nil {
-			return nil, err
-		}
-
-		allMonthsIssues = append(allMonthsIssues, sprintIssues...)
+		return false, err
 	}
+	return len(page.Issues) > 0, nil
+}
 
-	return allMonthsIssues, nil
+// GetAllBoardIssues wraps GetAllBoardIssuesWithContext using the background context.
+func (s *JiraService) GetAllBoardIssues(boardName string, issuesTypesFilter []string) ([]Issue, error) {
+	return s.GetAllBoardIssuesWithContext(context.Background(), boardName, issuesTypesFilter)
 }
 
-func (s *JiraService) GetSprintIssues(projectKey, boardName, sprintName string, issuesTypes []string) ([]Issue, error) {
+// GetAllBoardIssuesWithContext returns the issues of every sprint of the board
+// in sprint order, each issue once even if it was in several sprints.
+// Sprints are fetched in parallel; if some of them fail, the issues of the
+// others are returned together with the joined errors.
+func (s *JiraService) GetAllBoardIssuesWithContext(ctx context.Context, boardName string, issuesTypesFilter []string) ([]Issue, error) {
 	// First, find the board ID
-	board, err := s.GetBoard(boardName)
+	board, err := s.GetBoardWithContext(ctx, boardName)
 	if err != nil {
 		return nil, err
 	}
@@ -145,38 +343,53 @@ func (s *JiraService) GetSprintIssues(projectKey, boardName, sprintName string,
 	boardID := strconv.Itoa(board.ID)
 	log.Printf("Found board '%s' with ID %s", boardName, boardID)
 
+	_, sprintsIssues, err := s.walkBoardSprints(ctx, board.ID, boardName, createFilterMap(issuesTypesFilter), nil)
+
+	return dedupeIssues(sprintsIssues), err
+}
+
+// walkBoardSprints fetches the issues of the board's sprints that keep
+// accepts, or all of them when keep is nil. It returns the sprints walked
+// and their issues in the same order; failures are handled as by
+// loadSprintsIssues.
+func (s *JiraService) walkBoardSprints(ctx context.Context, boardID int, boardName string, typeFilter map[string]struct{}, keep func(jira.Sprint) bool) ([]jira.Sprint, [][]Issue, error) {
 	// Get all sprints for the board
-	sprints, _, err := s.client.Board.GetAllSprints(boardID)
+	all, err := s.boardSprints(ctx, boardID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sprints: %w", err)
+		return nil, nil, fmt.Errorf("failed to get sprints: %w", err)
 	}
 
-	log.Printf("Found %d sprints for board '%s'", len(sprints), boardName)
+	log.Printf("Found %d sprints for board '%s'", len(all), boardName)
 
-	var targetSprint *jira.Sprint
-	for _, sprint := range sprints {
-		if sprint.Name == sprintName {
-			targetSprint = &sprint
-			break
+	var sprints []jira.Sprint
+	for _, sprint := range all {
+		if keep == nil || keep(sprint) {
+			sprints = append(sprints, sprint)
+			log.Printf("Sprint: ID=%d, Name=%s, State=%s", sprint.ID, sprint.Name, sprint.State)
 		}
 	}
 
-	if targetSprint == nil {
-		return nil, fmt.Errorf("sprint '%s' not found", sprintName)
-	}
+	sprintsIssues, err := s.loadSprintsIssues(ctx, sprints, typeFilter)
+	return sprints, sprintsIssues, err
+}
 
-	log.Printf("Found sprint '%s' with ID %d", sprintName, targetSprint.ID)
+// GetSprintIssues wraps GetSprintIssuesWithContext using the background context.
+func (s *JiraService) GetSprintIssues(boardName, sprintName string, issuesTypes []string) ([]Issue, error) {
+	return s.GetSprintIssuesWithContext(context.Background(), boardName, sprintName, issuesTypes)
+}
 
-	// Fetch epic summaries for the collected epic keys
-	epicNames, err := s.LoadEpics(projectKey)
+// GetSprintIssuesWithContext returns the issues of the board's sprint picked
+// by the selector, see FindSprintWithContext
+func (s *JiraService) GetSprintIssuesWithContext(ctx context.Context, boardName, sprintName string, issuesTypes []string) ([]Issue, error) {
+	sprint, err := s.FindSprintWithContext(ctx, boardName, sprintName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load epics: %w", err)
+		return nil, err
 	}
 
 	// Prepare a filter map from issuesTypes (if provided) for O(1) checks
 	typeFilter := createFilterMap(issuesTypes)
 
-	result, err := s.LoadIssuesFromSprint(targetSprint.ID, epicNames, typeFilter)
+	result, err := s.LoadIssuesFromSprintWithContext(ctx, sprint.ID, typeFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -184,97 +397,46 @@ func (s *JiraService) GetSprintIssues(projectKey, boardName, sprintName string,
 	return result, nil
 }
 
-func (s *JiraService) LoadIssuesFromSprint(sprintId int, epicNames map[string]string, typeFilter map[string]struct{}) ([]Issue, error) {
+// LoadIssuesFromSprint wraps LoadIssuesFromSprintWithContext using the background context.
+func (s *JiraService) LoadIssuesFromSprint(sprintId int, typeFilter map[string]struct{}) ([]Issue, error) {
+	return s.LoadIssuesFromSprintWithContext(context.Background(), sprintId, typeFilter)
+}
+
+// LoadIssuesFromSprintWithContext returns the issues of the sprint that pass the type filter
+func (s *JiraService) LoadIssuesFromSprintWithContext(ctx context.Context, sprintId int, typeFilter map[string]struct{}) ([]Issue, error) {
 	// Get issues in the sprint
-	issues, _, err := s.client.Sprint.GetIssuesForSprint(sprintId)
+	issues, err := s.sprintIssues(ctx, sprintId, jql.Clause{}, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get sprint issues: %w", err)
 	}
+	issues = filterByType(issues, typeFilter)
+
+	// Resolve the names of the epics these issues belong to
+	epicNames, err := s.resolveEpicNames(ctx, collectEpicKeys(issues, s.epicField))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve epics: %w", err)
+	}
 
 	var result []Issue
 	for _, issue := range issues {
-		issueType := issue.Fields.Type.Name
-		epicName := getEpicName(issue, s.epicField, epicNames)
-		storyPoints := getStoryPoints(issue, s.spField)
-
-		// If a filter was provided, only include matching types (case-insensitive)
-		if len(typeFilter) > 0 {
-			if _, ok := typeFilter[strings.ToLower(strings.TrimSpace(issueType))]; !ok {
-				// skip this issue because its type is not in the filter list
-				continue
-			}
-		}
-
-		result = append(result, Issue{
-			Key:         issue.Key,
-			Summary:     issue.Fields.Summary,
-			Epic:        epicName,
-			StoryPoints: storyPoints,
-			Type:        issueType,
-			Status:      issue.Fields.Status.Name,
-			URL:         fmt.Sprintf("%s/browse/%s", s.url, issue.Key),
-		})
+		result = append(result, s.newIssue(issue, epicNames))
 	}
 
 	return result, nil
 }
 
-func getStoryPoints(issue jira.Issue, spFieldName string) float64 {
-	// Extract story points robustly (field may be float64, int, or string)
-	storyPoints := 0.0
-	if spField, ok := issue.Fields.Unknowns[spFieldName]; ok {
-		switch sp := spField.(type) {
-		case float64:
-			storyPoints = sp
-		case int:
-			storyPoints = float64(sp)
-		case int64:
-			storyPoints = float64(sp)
-		case string:
-			if f, err := strconv.ParseFloat(sp, 64); err == nil {
-				storyPoints = f
-			}
-		case map[string]interface{}:
-			// handle nested representations if present
-			if v, ok := sp["value"].(float64); ok {
-				storyPoints = v
-			} else if sstr, ok := sp["value"].(string); ok {
-				if f, err := strconv.ParseFloat(sstr, 64); err == nil {
-					storyPoints = f
-				}
-			}
-		}
-	}
-	return storyPoints
-}
-
-func getEpicName(issue jira.Issue, epicFieldName string, epicNames map[string]string) string {
-	epicName := ""
-	if v, ok := issue.Fields.Unknowns[epicFieldName]; ok {
-		switch t := v.(type) {
-		case string:
-			if name, found := epicNames[t]; found {
-				epicName = name
-			} else {
-				epicName = t
-			}
-		case map[string]interface{}:
-			var key string
-			if k, ok := t["key"].(string); ok {
-				key = k
-			} else if v2, ok := t["value"].(string); ok {
-				key = v2
-			}
-			if key != "" {
-				if name, found := epicNames[key]; found {
-					epicName = name
-				} else {
-					epicName = key
-				}
-			}
-		}
+// newIssue maps a raw Jira issue to an Issue using the field mapper
+func (s *JiraService) newIssue(issue jira.Issue, epicNames map[string]string) Issue {
+	if issue.Fields != nil && issue.Fields.Status != nil {
+		s.statuses.learn(*issue.Fields.Status)
 	}
-	return epicName
+	return s.mapper.Map(FieldSource{
+		Raw:            issue,
+		EpicNames:      epicNames,
+		BaseURL:        s.url,
+		StatusCategory: s.statuses.Category,
+		Location:       s.location,
+	})
 }
 
 func createFilterMap(issuesTypes []string) map[string]struct{} {
@@ -291,143 +453,143 @@ func createFilterMap(issuesTypes []string) map[string]struct{} {
 	return typeFilter
 }
 
-// GetIssuesInProgressDuringMonth returns issues that were in 'In Progress' status
-// during the specified month, regardless of their current status.
-// It checks the issue changelog to find when status changed to "In Progress".
-func (s *JiraService) GetIssuesInProgressDuringMonth(projectKey string, monthStart, monthEnd time.Time, issuesTypes []string) ([]Issue, error) {
-	// Format dates for JQL: YYYY-MM-DD
-	startStr := monthStart.Format("2006-01-02")
+// filterByType returns the issues whose type is in the type filter, ignoring
+// case, or all of them when the filter is empty
+func filterByType(issues []jira.Issue, typeFilter map[string]struct{}) []jira.Issue {
+	if len(typeFilter) == 0 {
+		return issues
+	}
+	var kept []jira.Issue
+	for _, issue := range issues {
+		if issue.Fields == nil {
+			continue
+		}
+		if _, ok := typeFilter[strings.ToLower(strings.TrimSpace(issue.Fields.Type.Name))]; ok {
+			kept = append(kept, issue)
+		}
+	}
+	return kept
+}
 
-	// JQL to find issues created or updated during the month in the project
-	// We'll then check their changelog for "In Progress" status changes
-	jql := fmt.Sprintf(`project = "%s" AND (created >= "%s" OR updated >= "%s")`, projectKey, startStr, startStr)
+// GetIssuesInProgressDuring wraps GetIssuesInProgressDuringWithContext using the background context.
+func (s *JiraService) GetIssuesInProgressDuring(projectKey string, start, end time.Time, issuesTypes []string) ([]Issue, error) {
+	return s.GetIssuesInProgressDuringWithContext(context.Background(), projectKey, start, end, issuesTypes)
+}
 
-	opts := &jira.SearchOptions{MaxResults: 1000, Expand: "changelog"}
-	jiraIssues, _, err := s.client.Issue.Search(jql, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search issues: %w", err)
-	}
+// GetIssuesInProgressDuringWithContext returns issues that were in an In Progress status
+// at any time within [start, end), regardless of their current status. It rebuilds the
+// status intervals from the issue changelog, so work that started before the range and
+// went on into it counts too.
+func (s *JiraService) GetIssuesInProgressDuringWithContext(ctx context.Context, projectKey string, start, end time.Time, issuesTypes []string) ([]Issue, error) {
+	return s.issuesInProgressDuring(ctx, jql.Eq("project", jql.String(projectKey)), start, end, issuesTypes)
+}
 
-	// Load epics for name resolution
-	epicNames, err := s.LoadEpics(projectKey)
+// GetFilterIssuesInProgressDuringWithContext is GetIssuesInProgressDuringWithContext
+// for the issues of a saved filter instead of a project
+func (s *JiraService) GetFilterIssuesInProgressDuringWithContext(ctx context.Context, filter *Filter, start, end time.Time, issuesTypes []string) ([]Issue, error) {
+	scope, err := filter.Clause()
 	if err != nil {
-		log.Printf("warning: failed to load epics: %v", err)
-		epicNames = make(map[string]string)
+		return nil, err
 	}
+	return s.issuesInProgressDuring(ctx, scope, start, end, issuesTypes)
+}
 
-	// Create type filter
-	typeFilter := createFilterMap(issuesTypes)
+// issuesInProgressDuring returns the issues within scope that were in an
+// In Progress status at any time within [start, end)
+func (s *JiraService) issuesInProgressDuring(ctx context.Context, scope jql.Clause, start, end time.Time, issuesTypes []string) ([]Issue, error) {
+	// Changelogs name statuses the issues are no longer in, classify them
+	// the way Jira does
+	if err := s.loadStatusCategories(ctx); err != nil {
+		return nil, err
+	}
 
-	var result []Issue
-	for _, jiraIssue := range jiraIssues {
-		// Determine issue type
-		issueType := jiraIssue.Fields.Type.Name
+	// Let Jira find the issues that were in progress during the range from
+	// the status history. Instances that reject the query get a broader one,
+	// the changelog check below is exact either way.
+	query := s.inProgressQuery(scope, start, end, issuesTypes, true)
+	jiraIssues, err := s.searchAll(ctx, query.String(), PageRequest{Expand: "changelog"})
+	if errors.Is(err, ErrInvalidQuery) {
+		log.Printf("warning: status history search failed, falling back to a broader query: %v", err)
+		query = s.inProgressQuery(scope, start, end, issuesTypes, false)
+		jiraIssues, err = s.searchAll(ctx, query.String(), PageRequest{Expand: "changelog"})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
 
-		// Apply type filter if provided
-		if len(typeFilter) > 0 {
-			if _, ok := typeFilter[strings.ToLower(strings.TrimSpace(issueType))]; !ok {
-				continue
+	var matched []jira.Issue
+	for _, jiraIssue := range filterByType(jiraIssues, createFilterMap(issuesTypes)) {
+		// Some search endpoints omit the expanded changelog, fetch it separately
+		if jiraIssue.Changelog == nil {
+			changelog, err := s.client.GetIssueChangelog(ctx, jiraIssue.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get changelog for %s: %w", jiraIssue.Key, err)
 			}
+			jiraIssue.Changelog = changelog
 		}
 
-		// Check if this issue was in 'In Progress' status during the target month
-		wasInProgressDuringMonth := false
-		if jiraIssue.Changelog != nil {
-			for _, history := range jiraIssue.Changelog.Histories {
-				// Parse the created timestamp
-				createdTime, err := time.Parse("2006-01-02T15:04:05.000-0700", history.Created)
-				if err != nil {
-					// Try alternate format
-					createdTime, err = time.Parse(time.RFC3339, history.Created)
-					if err != nil {
-						log.Printf("warning: could not parse changelog timestamp %s: %v", history.Created, err)
-						continue
-					}
-				}
-
-				// Check if this change happened during the target month
-				if createdTime.Before(monthEnd) && !createdTime.Before(monthStart) {
-					// Look for status changes to "In Progress"
-					for _, item := range history.Items {
-						if item.Field == "status" && item.ToString == "In Progress" {
-							wasInProgressDuringMonth = true
-							break
-						}
-					}
-				}
-
-				if wasInProgressDuringMonth {
-					break
-				}
-			}
+		// Check if this issue was in an active status at any time during the
+		// range, including work started before the range began
+		var status string
+		if jiraIssue.Fields.Status != nil {
+			status = jiraIssue.Fields.Status.Name
 		}
-
-		// Skip if not in progress during month
-		if !wasInProgressDuringMonth {
+		timeline := BuildStatusTimeline(time.Time(jiraIssue.Fields.Created), status, jiraIssue.Changelog)
+		if !WasInCategoryDuring(timeline, s.statuses.Category, StatusInProgress, start, end) {
 			continue
 		}
 
-		// Resolve epic name
-		epicName := ""
-		if v, ok := jiraIssue.Fields.Unknowns[s.epicField]; ok {
-			switch t := v.(type) {
-			case string:
-				if name, found := epicNames[t]; found {
-					epicName = name
-				} else {
-					epicName = t
-				}
-			case map[string]interface{}:
-				var key string
-				if k, ok := t["key"].(string); ok {
-					key = k
-				} else if v2, ok := t["value"].(string); ok {
-					key = v2
-				}
-				if key != "" {
-					if name, found := epicNames[key]; found {
-						epicName = name
-					} else {
-						epicName = key
-					}
-				}
-			}
-		}
+		matched = append(matched, jiraIssue)
+	}
 
-		// Extract story points
-		storyPoints := 0.0
-		if spField, ok := jiraIssue.Fields.Unknowns[s.spField]; ok {
-			switch sp := spField.(type) {
-			case float64:
-				storyPoints = sp
-			case int:
-				storyPoints = float64(sp)
-			case int64:
-				storyPoints = float64(sp)
-			case string:
-				if f, err := strconv.ParseFloat(sp, 64); err == nil {
-					storyPoints = f
-				}
-			case map[string]interface{}:
-				if v, ok := sp["value"].(float64); ok {
-					storyPoints = v
-				} else if sstr, ok := sp["value"].(string); ok {
-					if f, err := strconv.ParseFloat(sstr, 64); err == nil {
-						storyPoints = f
-					}
-				}
-			}
-		}
+	// Resolve the names of the epics the matched issues belong to
+	epicNames, err := s.resolveEpicNames(ctx, collectEpicKeys(matched, s.epicField))
+	if err != nil {
+		log.Printf("warning: failed to resolve epics: %v", err)
+		epicNames = make(map[string]string)
+	}
+
+	var result []Issue
+	for _, jiraIssue := range matched {
+		result = append(result, s.newIssue(jiraIssue, epicNames))
+	}
+
+	return result, nil
+}
+
+// GetIssuesByJQLWithContext returns the issues matching a JQL query written by
+// a user, in the query's order. The query is narrowed to the issue types, if
+// any, and to the service's extra JQL.
+func (s *JiraService) GetIssuesByJQLWithContext(ctx context.Context, query string, issuesTypes []string) ([]Issue, error) {
+	where, orderBy, err := jql.Split(query)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JQL: %w", err)
+	}
+	clause, err := jql.Raw(where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JQL: %w", err)
+	}
+
+	search := jql.Query{
+		Where:    jql.And(clause, jql.In("issuetype", jql.Strings(issuesTypes)...), s.extraJQL),
+		RawOrder: orderBy,
+	}
+
+	jiraIssues, err := s.searchAll(ctx, search.String(), PageRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
 
-		result = append(result, Issue{
-			Key:         jiraIssue.Key,
-			Summary:     jiraIssue.Fields.Summary,
-			Epic:        epicName,
-			StoryPoints: storyPoints,
-			Type:        issueType,
-			Status:      jiraIssue.Fields.Status.Name,
-			URL:         fmt.Sprintf("%s/browse/%s", s.url, jiraIssue.Key),
-		})
+	// Resolve the names of the epics the issues belong to
+	epicNames, err := s.resolveEpicNames(ctx, collectEpicKeys(jiraIssues, s.epicField))
+	if err != nil {
+		log.Printf("warning: failed to resolve epics: %v", err)
+		epicNames = make(map[string]string)
+	}
+
+	result := make([]Issue, 0, len(jiraIssues))
+	for _, jiraIssue := range jiraIssues {
+		result = append(result, s.newIssue(jiraIssue, epicNames))
 	}
 
 	return result, nil
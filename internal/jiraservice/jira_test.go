@@ -0,0 +1,38 @@
+package jiraservice
+
+import (
+	"reflect"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+func TestFilterByType(t *testing.T) {
+	issue := func(key, issueType string) jira.Issue {
+		return jira.Issue{Key: key, Fields: &jira.IssueFields{Type: jira.IssueType{Name: issueType}}}
+	}
+	issues := []jira.Issue{issue("PROJ-1", "Bug"), issue("PROJ-2", "Story"), issue("PROJ-3", " task "), {Key: "PROJ-4"}}
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []string
+	}{
+		{name: "no types", want: []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4"}},
+		{name: "blank types", types: []string{" ", ""}, want: []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4"}},
+		{name: "ignoring case and spaces", types: []string{"BUG", "Task"}, want: []string{"PROJ-1", "PROJ-3"}},
+		{name: "no match", types: []string{"Epic"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, issue := range filterByType(issues, createFilterMap(tt.types)) {
+				got = append(got, issue.Key)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
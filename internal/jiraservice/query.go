@@ -0,0 +1,36 @@
+package jiraservice
+
+import (
+	"time"
+
+	"go-word-create/internal/jql"
+)
+
+// inProgressQuery returns the JQL for the issues within scope, e.g. a
+// project, that may have been in an in-progress status within [start, end).
+// The status history query lets Jira do the filtering; without it, issues
+// are narrowed down to those created before the end and touched since the
+// start or still in progress.
+//
+// JQL dates are read in the Jira user's time zone, so the range is widened
+// by a day on each side and the exact bounds are applied to the changelog
+// afterwards.
+func (s *JiraService) inProgressQuery(scope jql.Clause, start, end time.Time, issuesTypes []string, useHistory bool) jql.Clause {
+	from := jql.Date(start.AddDate(0, 0, -1))
+	to := jql.Date(end.AddDate(0, 0, 1))
+
+	var period jql.Clause
+	if names := s.statuses.names(StatusInProgress); useHistory && len(names) > 0 {
+		period = jql.WasInDuring("status", jql.Strings(names), from, to)
+	} else {
+		period = jql.And(
+			jql.Compare("created", "<", to),
+			jql.Or(jql.Compare("updated", ">=", from), s.activeStatusClause()))
+	}
+
+	return jql.And(
+		scope,
+		jql.In("issuetype", jql.Strings(issuesTypes)...),
+		period,
+		s.extraJQL)
+}
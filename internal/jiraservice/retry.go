@@ -0,0 +1,172 @@
+package jiraservice
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how idempotent Jira calls are retried after rate
+// limiting (429) or transient server errors
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on every
+	// following retry
+	BaseDelay time.Duration
+	// MaxDelay caps a single backoff. Retry-After waits are not capped; a
+	// wait beyond MaxElapsed gives up instead.
+	MaxDelay time.Duration
+	// MaxElapsed is the total time budget across all attempts. Zero means
+	// only MaxRetries limits retrying.
+	MaxElapsed time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 5,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+		MaxElapsed: 2 * time.Minute,
+	}
+}
+
+// RetryError is returned once a call has failed on every attempt allowed by
+// the retry policy
+type RetryError struct {
+	Method   string
+	URL      string
+	Attempts int
+	// StatusCode is the status of the last response, 0 if the last attempt
+	// failed without a response
+	StatusCode int
+	// Err is the transport error of the last attempt, if any
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s %s: giving up after %d attempts: %v", e.Method, e.URL, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("%s %s: giving up after %d attempts: %d %s", e.Method, e.URL, e.Attempts, e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retryTransport retries idempotent requests with exponential backoff and
+// jitter, honouring Retry-After
+type retryTransport struct {
+	policy RetryPolicy
+	next   http.RoundTripper
+}
+
+func newRetryTransport(policy RetryPolicy, next http.RoundTripper) *retryTransport {
+	return &retryTransport{policy: policy, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req.Method) {
+		return t.next.RoundTrip(req)
+	}
+
+	start := time.Now()
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err != nil && req.Context().Err() != nil {
+			// Cancelled by the caller, not a transient failure
+			return nil, err
+		}
+
+		retryErr := &RetryError{
+			Method:   req.Method,
+			URL:      req.URL.Path,
+			Attempts: attempt + 1,
+			Err:      err,
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			retryErr.StatusCode = resp.StatusCode
+			if after, ok := retryAfter(resp); ok {
+				delay = after
+			}
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if attempt >= t.policy.MaxRetries {
+			return nil, retryErr
+		}
+		if t.policy.MaxElapsed > 0 && time.Since(start)+delay > t.policy.MaxElapsed {
+			return nil, retryErr
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the exponential delay before the given retry with equal
+// jitter, so concurrent clients don't retry in lockstep
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	delay := t.policy.BaseDelay << attempt
+	if delay <= 0 || delay > t.policy.MaxDelay {
+		delay = t.policy.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// retryAfter parses the Retry-After header as seconds or an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
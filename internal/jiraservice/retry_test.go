@@ -0,0 +1,217 @@
+package jiraservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubServer answers with the statuses in turn, repeating the last one, and
+// counts the requests
+type stubServer struct {
+	statuses   []int
+	retryAfter string
+	requests   atomic.Int32
+}
+
+func (s *stubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(s.requests.Add(1))
+	status := s.statuses[min(n, len(s.statuses))-1]
+	if s.retryAfter != "" && status == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", s.retryAfter)
+	}
+	w.WriteHeader(status)
+}
+
+// fastPolicy retries quickly so the tests don't wait on backoff
+func fastPolicy(maxRetries int) RetryPolicy {
+	return RetryPolicy{MaxRetries: maxRetries, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+}
+
+// roundTrip sends a request with the method through a retry transport with
+// the policy to a server running the stub
+func roundTrip(t *testing.T, ctx context.Context, stub *stubServer, policy RetryPolicy, method string) (*http.Response, error) {
+	t.Helper()
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	req, err := http.NewRequestWithContext(ctx, method, srv.URL+"/rest/api/2/search", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := newRetryTransport(policy, http.DefaultTransport).RoundTrip(req)
+	if resp != nil {
+		t.Cleanup(func() { resp.Body.Close() })
+	}
+	return resp, err
+}
+
+func TestRetryTransportRetriesServerErrors(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK}}
+	resp, err := roundTrip(t, context.Background(), stub, fastPolicy(5), http.MethodGet)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	if got := stub.requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestRetryTransportGivesUpAfterMaxRetries(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusInternalServerError}}
+	_, err := roundTrip(t, context.Background(), stub, fastPolicy(2), http.MethodGet)
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("got error %v, want a *RetryError", err)
+	}
+	if retryErr.Attempts != 3 || retryErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got %d attempts ending in %d, want 3 ending in 500", retryErr.Attempts, retryErr.StatusCode)
+	}
+	if got := stub.requests.Load(); got != 3 {
+		t.Errorf("got %d requests, want 3", got)
+	}
+}
+
+func TestRetryTransportHonoursRetryAfter(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusTooManyRequests, http.StatusOK}, retryAfter: "1"}
+	// Retry-After is waited in full, even beyond MaxDelay
+	policy := fastPolicy(3)
+
+	start := time.Now()
+	resp, err := roundTrip(t, context.Background(), stub, policy, http.MethodGet)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s of Retry-After", elapsed)
+	}
+}
+
+func TestRetryTransportGivesUpWhenRetryAfterExceedsMaxElapsed(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusTooManyRequests, http.StatusOK}, retryAfter: "60"}
+	policy := fastPolicy(3)
+	policy.MaxElapsed = time.Second
+
+	start := time.Now()
+	_, err := roundTrip(t, context.Background(), stub, policy, http.MethodGet)
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("got error %v, want a *RetryError", err)
+	}
+	if retryErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got status %d, want 429", retryErr.StatusCode)
+	}
+	if got := stub.requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+	if elapsed := time.Since(start); elapsed >= policy.MaxElapsed {
+		t.Errorf("gave up after %v, want without waiting", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		min    time.Duration
+		max    time.Duration
+		ok     bool
+	}{
+		{name: "seconds", header: "120", min: 2 * time.Minute, max: 2 * time.Minute, ok: true},
+		{name: "zero seconds", header: "0", ok: true},
+		{name: "HTTP date", header: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute, ok: true},
+		{name: "HTTP date in the past", header: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), ok: true},
+		{name: "missing", header: ""},
+		{name: "invalid", header: "soon"},
+		{name: "negative seconds", header: "-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			got, ok := retryAfter(resp)
+			if ok != tt.ok {
+				t.Fatalf("got ok %v, want %v", ok, tt.ok)
+			}
+			if got < tt.min || got > tt.max {
+				t.Errorf("got %v, want between %v and %v", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestRetryTransportStopsAtMaxElapsed(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusServiceUnavailable}}
+	policy := RetryPolicy{MaxRetries: 100, BaseDelay: 20 * time.Millisecond, MaxDelay: 20 * time.Millisecond, MaxElapsed: 50 * time.Millisecond}
+
+	start := time.Now()
+	_, err := roundTrip(t, context.Background(), stub, policy, http.MethodGet)
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("got error %v, want a *RetryError", err)
+	}
+	if got := stub.requests.Load(); got >= 10 {
+		t.Errorf("got %d requests, want the time budget to stop retrying early", got)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("gave up after %v, want about %v", elapsed, policy.MaxElapsed)
+	}
+}
+
+func TestRetryTransportPassesNonIdempotentRequests(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusServiceUnavailable}}
+	resp, err := roundTrip(t, context.Background(), stub, fastPolicy(5), http.MethodPost)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want 503", resp.StatusCode)
+	}
+	if got := stub.requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestRetryTransportStopsWhenCancelledDuringBackoff(t *testing.T) {
+	stub := &stubServer{statuses: []int{http.StatusServiceUnavailable}}
+	policy := RetryPolicy{MaxRetries: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := roundTrip(t, ctx, stub, policy, http.MethodGet)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want the context's", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want right after the cancellation", elapsed)
+	}
+	if got := stub.requests.Load(); got != 1 {
+		t.Errorf("got %d requests, want 1", got)
+	}
+}
+
+func TestRetryErrorMessage(t *testing.T) {
+	err := &RetryError{Method: http.MethodGet, URL: "/rest/api/2/search", Attempts: 3, StatusCode: http.StatusTooManyRequests}
+	if got := err.Error(); !strings.Contains(got, "3 attempts") || !strings.Contains(got, "429 Too Many Requests") {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,404 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// MembershipInterval is a period an issue spent in a sprint
+type MembershipInterval struct {
+	From time.Time
+	// To is zero while the issue is still in the sprint
+	To time.Time
+}
+
+// contains reports whether the interval covers the instant t
+func (i MembershipInterval) contains(t time.Time) bool {
+	return !i.From.After(t) && (i.To.IsZero() || i.To.After(t))
+}
+
+// sprintChange is a change of the Sprint field read from the changelog
+type sprintChange struct {
+	at       time.Time
+	from, to []int
+}
+
+// sprintChanges returns the Sprint field changes of the changelog, oldest
+// first
+func sprintChanges(changelog *jira.Changelog) []sprintChange {
+	if changelog == nil {
+		return nil
+	}
+
+	var changes []sprintChange
+	for _, history := range changelog.Histories {
+		at, err := parseChangelogTime(history.Created)
+		if err != nil {
+			log.Printf("warning: could not parse changelog timestamp %s: %v", history.Created, err)
+			continue
+		}
+		for _, item := range history.Items {
+			if strings.EqualFold(item.Field, "Sprint") {
+				changes = append(changes, sprintChange{at: at, from: sprintIDs(item.From), to: sprintIDs(item.To)})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].at.Before(changes[j].at)
+	})
+	return changes
+}
+
+// sprintIDs parses the comma separated sprint IDs of a changelog item
+func sprintIDs(v interface{}) []int {
+	if n, ok := v.(float64); ok {
+		return []int{int(n)}
+	}
+	s, _ := v.(string)
+
+	var ids []int
+	for _, part := range strings.Split(s, ",") {
+		if id, err := strconv.Atoi(strings.TrimSpace(part)); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func hasSprint(ids []int, sprintID int) bool {
+	for _, id := range ids {
+		if id == sprintID {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildSprintMembership reconstructs when an issue was in the sprint from
+// the Sprint field changes of its changelog. Without any change the issue
+// has been in the sprint since its creation if it is in it now.
+func BuildSprintMembership(created time.Time, sprintID int, inSprintNow bool, changelog *jira.Changelog) []MembershipInterval {
+	changes := sprintChanges(changelog)
+
+	in := inSprintNow
+	if len(changes) > 0 {
+		in = hasSprint(changes[0].from, sprintID)
+	}
+
+	var intervals []MembershipInterval
+	var from time.Time
+	if in {
+		from = created
+	}
+	for _, change := range changes {
+		now := hasSprint(change.to, sprintID)
+		switch {
+		case now && !in:
+			from = change.at
+		case !now && in:
+			intervals = append(intervals, MembershipInterval{From: from, To: change.at})
+		}
+		in = now
+	}
+	if in {
+		intervals = append(intervals, MembershipInterval{From: from})
+	}
+	return intervals
+}
+
+// inSprintAt reports whether the membership covers the instant t
+func inSprintAt(membership []MembershipInterval, t time.Time) bool {
+	for _, interval := range membership {
+		if interval.contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// inSprintDuring reports whether the issue was in the sprint at any time
+// within [start, end)
+func inSprintDuring(membership []MembershipInterval, start, end time.Time) bool {
+	for _, interval := range membership {
+		if interval.From.Before(end) && (interval.To.IsZero() || interval.To.After(start)) {
+			return true
+		}
+	}
+	return false
+}
+
+// statusAt returns the status of the timeline at the instant t
+func statusAt(timeline []StatusInterval, t time.Time) string {
+	status := ""
+	for _, interval := range timeline {
+		if interval.From.After(t) {
+			break
+		}
+		status = interval.Status
+	}
+	return status
+}
+
+// SprintScope groups the issues of a sprint by how they came to be in it
+type SprintScope struct {
+	Sprint jira.Sprint
+	// Start and End bound the sprint; End is when it was completed, or now
+	// while it is still active
+	Start time.Time
+	End   time.Time
+	// Committed were in the sprint when it started and still are at its end
+	Committed []Issue
+	// Added joined the sprint after it started and are still in it
+	Added []Issue
+	// Removed were in the sprint at some point but left before its end
+	Removed []Issue
+	// Initial were in the sprint when it started, including those removed
+	// later. Jira reports them as the sprint's commitment.
+	Initial []Issue
+	// CarriedOver were still in the sprint but not done when it was
+	// completed, so they move on to a later sprint. They are also listed as
+	// committed or added. Empty while the sprint is active.
+	CarriedOver []Issue
+	// Burndown samples the sprint's story points from its start to its
+	// end, at the beginning of every day
+	Burndown []BurndownPoint
+	// CarriedIn were in earlier sprints of the board before being in this
+	// one, and still are in it. Only set by AddSprintHistory.
+	CarriedIn []Issue
+	// StartPoints and EndPoints are the story points of each issue when the
+	// sprint started and ended, by key, read from the changelog like the
+	// burndown's. Re-estimating an issue later changes neither.
+	StartPoints map[string]float64
+	EndPoints   map[string]float64
+
+	// changelogSprints holds the sprint IDs each issue's Sprint field has
+	// had, by issue key
+	changelogSprints map[string][]int
+}
+
+// GetSprintScopeWithContext reconstructs which issues were committed to the
+// sprint, added to it or removed from it, from the Sprint field changelog of
+// the issues and the sprint's dates. Issues that have left the sprint are
+// searched for among the project's issues updated since the sprint started.
+func (s *JiraService) GetSprintScopeWithContext(ctx context.Context, projectKey string, sprint *jira.Sprint, issuesTypes []string) (*SprintScope, error) {
+	return s.sprintScope(ctx, jql.Eq("project", jql.String(projectKey)), jql.Clause{}, sprint, issuesTypes)
+}
+
+// sprintScope reconstructs the sprint's scope from the sprint's issues
+// matching narrow and the issues within scope that may have left it
+func (s *JiraService) sprintScope(ctx context.Context, within, narrow jql.Clause, sprint *jira.Sprint, issuesTypes []string) (*SprintScope, error) {
+	if sprint.StartDate == nil {
+		return nil, fmt.Errorf("sprint '%s' has not started", sprint.Name)
+	}
+	scope := &SprintScope{
+		Sprint:           *sprint,
+		Start:            *sprint.StartDate,
+		End:              time.Now(),
+		StartPoints:      make(map[string]float64),
+		EndPoints:        make(map[string]float64),
+		changelogSprints: make(map[string][]int),
+	}
+	closed := sprint.State == "closed"
+	if closed {
+		scope.End = sprintClosedAt(*sprint)
+	}
+
+	if err := s.loadStatusCategories(ctx); err != nil {
+		return nil, err
+	}
+
+	current, err := s.sprintIssues(ctx, sprint.ID, narrow, "changelog")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint issues: %w", err)
+	}
+	inSprintNow := make(map[string]struct{}, len(current))
+	for _, issue := range current {
+		inSprintNow[issue.Key] = struct{}{}
+	}
+
+	// Removing an issue from the sprint updates it, so the issues that left
+	// were updated after the start. Open sprints have no upper bound, so the
+	// query stays the same from day to day and replays from a cassette.
+	var createdBefore jql.Clause
+	if closed {
+		createdBefore = jql.Compare("created", "<", jql.Date(scope.End.AddDate(0, 0, 1)))
+	}
+	query := jql.And(
+		within,
+		jql.In("issuetype", jql.Strings(issuesTypes)...),
+		jql.Compare("updated", ">=", jql.Date(scope.Start.AddDate(0, 0, -1))),
+		createdBefore,
+		s.extraJQL)
+	touched, err := s.searchAll(ctx, query.String(), PageRequest{Expand: "changelog"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search issues: %w", err)
+	}
+
+	candidates := current
+	for _, issue := range touched {
+		if _, ok := inSprintNow[issue.Key]; !ok {
+			candidates = append(candidates, issue)
+		}
+	}
+
+	type placed struct {
+		issue                           jira.Issue
+		committed, removed, carriedOver bool
+	}
+	var matched []placed
+	var history []burndownIssue
+	for _, jiraIssue := range filterByType(candidates, createFilterMap(issuesTypes)) {
+		// Some endpoints omit the expanded changelog, fetch it separately
+		if jiraIssue.Changelog == nil {
+			changelog, err := s.client.GetIssueChangelog(ctx, jiraIssue.Key)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get changelog for %s: %w", jiraIssue.Key, err)
+			}
+			jiraIssue.Changelog = changelog
+		}
+
+		_, now := inSprintNow[jiraIssue.Key]
+		created := time.Time(jiraIssue.Fields.Created)
+		membership := BuildSprintMembership(created, sprint.ID, now, jiraIssue.Changelog)
+		if !inSprintDuring(membership, scope.Start, scope.End) {
+			continue
+		}
+
+		var status string
+		if jiraIssue.Fields.Status != nil {
+			status = jiraIssue.Fields.Status.Name
+		}
+		timeline := BuildStatusTimeline(created, status, jiraIssue.Changelog)
+
+		p := placed{issue: jiraIssue, committed: inSprintAt(membership, scope.Start)}
+		atEnd := now
+		if closed {
+			atEnd = inSprintAt(membership, scope.End)
+		}
+		p.removed = !atEnd
+		p.carriedOver = closed && atEnd && s.statuses.Category(statusAt(timeline, scope.End)) != StatusDone
+		matched = append(matched, p)
+
+		for _, change := range sprintChanges(jiraIssue.Changelog) {
+			scope.changelogSprints[jiraIssue.Key] = append(scope.changelogSprints[jiraIssue.Key], change.from...)
+			scope.changelogSprints[jiraIssue.Key] = append(scope.changelogSprints[jiraIssue.Key], change.to...)
+		}
+
+		current, _ := FieldNumber(jiraIssue.Fields.Unknowns[s.spField])
+		points := pointsChanges(jiraIssue.Changelog, s.spField)
+		scope.StartPoints[jiraIssue.Key] = pointsAt(points, current, scope.Start)
+		scope.EndPoints[jiraIssue.Key] = pointsAt(points, current, scope.End)
+		history = append(history, burndownIssue{
+			membership: membership,
+			timeline:   timeline,
+			points:     points,
+			current:    current,
+		})
+	}
+
+	plannedEnd := scope.End
+	if sprint.EndDate != nil {
+		plannedEnd = *sprint.EndDate
+	}
+	scope.Burndown = s.buildBurndown(history, scope.Start, scope.End, plannedEnd)
+
+	raw := make([]jira.Issue, 0, len(matched))
+	for _, p := range matched {
+		raw = append(raw, p.issue)
+	}
+	epicNames, err := s.resolveEpicNames(ctx, collectEpicKeys(raw, s.epicField))
+	if err != nil {
+		log.Printf("warning: failed to resolve epics: %v", err)
+		epicNames = make(map[string]string)
+	}
+
+	for _, p := range matched {
+		issue := s.newIssue(p.issue, epicNames)
+		switch {
+		case p.removed:
+			scope.Removed = append(scope.Removed, issue)
+		case p.committed:
+			scope.Committed = append(scope.Committed, issue)
+		default:
+			scope.Added = append(scope.Added, issue)
+		}
+		if p.committed {
+			scope.Initial = append(scope.Initial, issue)
+		}
+		if p.carriedOver {
+			scope.CarriedOver = append(scope.CarriedOver, issue)
+		}
+	}
+
+	return scope, nil
+}
+
+// GetClosedSprintScopesWithContext returns the scopes of the board's last
+// count closed sprints, oldest first. Sprints are reconstructed in parallel
+// and, as velocity needs all of them, any failure fails the whole call.
+func (s *JiraService) GetClosedSprintScopesWithContext(ctx context.Context, projectKey, boardName string, count int, issuesTypes []string) ([]SprintScope, error) {
+	return s.closedSprintScopes(ctx, jql.Eq("project", jql.String(projectKey)), jql.Clause{}, boardName, count, issuesTypes)
+}
+
+// GetFilterClosedSprintScopesWithContext is GetClosedSprintScopesWithContext
+// for the issues of a saved filter instead of a project
+func (s *JiraService) GetFilterClosedSprintScopesWithContext(ctx context.Context, filter *Filter, boardName string, count int, issuesTypes []string) ([]SprintScope, error) {
+	clause, err := filter.Clause()
+	if err != nil {
+		return nil, err
+	}
+	return s.closedSprintScopes(ctx, clause, clause, boardName, count, issuesTypes)
+}
+
+// closedSprintScopes returns the scopes of the board's last count closed
+// sprints, as sprintScope reconstructs them
+func (s *JiraService) closedSprintScopes(ctx context.Context, within, narrow jql.Clause, boardName string, count int, issuesTypes []string) ([]SprintScope, error) {
+	board, err := s.GetBoardWithContext(ctx, boardName)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Found board '%s' with ID %d", boardName, board.ID)
+
+	sprints, err := s.boardSprints(ctx, board.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprints: %w", err)
+	}
+
+	var closed []jira.Sprint
+	for _, sprint := range sprints {
+		if sprint.State == "closed" && sprint.StartDate != nil {
+			closed = append(closed, sprint)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool {
+		return sprintClosedAt(closed[i]).Before(sprintClosedAt(closed[j]))
+	})
+	if count > 0 && len(closed) > count {
+		closed = closed[len(closed)-count:]
+	}
+	log.Printf("Found %d closed sprints for board '%s'", len(closed), boardName)
+
+	scopes := make([]SprintScope, len(closed))
+	err = s.forEachSprint(ctx, closed, func(i int, sprint jira.Sprint) error {
+		scope, err := s.sprintScope(ctx, within, narrow, &sprint, issuesTypes)
+		if err != nil {
+			return err
+		}
+		scopes[i] = *scope
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scopes, nil
+}
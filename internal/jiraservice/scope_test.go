@@ -0,0 +1,62 @@
+package jiraservice
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// queryRecorder is a fake client remembering the JQL of its searches
+type queryRecorder struct {
+	*FakeClient
+	queries []string
+}
+
+func (r *queryRecorder) SearchIssues(ctx context.Context, jql string, req PageRequest) (*IssuePage, error) {
+	r.queries = append(r.queries, jql)
+	return r.FakeClient.SearchIssues(ctx, jql, req)
+}
+
+func TestSprintScopeQueryHasNoUpperBoundForOpenSprints(t *testing.T) {
+	tests := []struct {
+		sprint      string
+		wantCreated bool
+	}{
+		{sprint: "Sprint 16", wantCreated: false},
+		{sprint: "Sprint 15", wantCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sprint, func(t *testing.T) {
+			fake, err := LoadFakeClient(fixturesDir)
+			if err != nil {
+				t.Fatalf("failed to load fixtures: %v", err)
+			}
+			recorder := &queryRecorder{FakeClient: fake}
+			s := NewJiraServiceWithClient(recorder, "https://jira.example.com", "customfield_14500", "customfield_10004")
+			ctx := context.Background()
+
+			sprint, err := s.FindSprintWithContext(ctx, "Team Board", tt.sprint)
+			if err != nil {
+				t.Fatalf("FindSprint: %v", err)
+			}
+			if _, err := s.GetSprintScopeWithContext(ctx, "PROJ", sprint, []string{"Bug", "Feature", "Task"}); err != nil {
+				t.Fatalf("GetSprintScope: %v", err)
+			}
+
+			// The search for issues that may have left the sprint
+			var query string
+			for _, q := range recorder.queries {
+				if strings.Contains(q, "updated >=") {
+					query = q
+				}
+			}
+			if query == "" {
+				t.Fatalf("no search for issues updated since the start in %q", recorder.queries)
+			}
+			if got := strings.Contains(query, "created <"); got != tt.wantCreated {
+				t.Errorf("query %q bounds creation %v, want %v", query, got, tt.wantCreated)
+			}
+		})
+	}
+}
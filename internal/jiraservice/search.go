@@ -0,0 +1,195 @@
+package jiraservice
+
+import (
+	"context"
+	"fmt"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// pageSize is the number of results requested per page. Jira is free to
+// return fewer (Cloud caps most endpoints at 50 or 100), so paging always
+// follows what the response reports rather than what was asked for.
+const pageSize = 100
+
+// pageFetcher requests a single page of issues
+type pageFetcher func(req PageRequest) (*IssuePage, error)
+
+// paginate calls fetch until the last page has been read and hands every
+// issue to fn. Pages are followed by nextPageToken when Jira supplies one
+// and by startAt/total otherwise.
+func paginate(req PageRequest, fetch pageFetcher, fn func(jira.Issue) error) error {
+	req.StartAt = 0
+	req.NextPageToken = ""
+	req.MaxResults = pageSize
+
+	for {
+		page, err := fetch(req)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range page.Issues {
+			if err := fn(issue); err != nil {
+				return err
+			}
+		}
+
+		if len(page.Issues) == 0 || page.IsLast {
+			return nil
+		}
+
+		if page.NextPageToken != "" {
+			req.NextPageToken = page.NextPageToken
+			continue
+		}
+
+		req.StartAt = page.StartAt + len(page.Issues)
+		if req.StartAt >= page.Total {
+			return nil
+		}
+	}
+}
+
+// searchIssues runs the JQL query and calls fn for every matching issue
+// across all result pages
+func (s *JiraService) searchIssues(ctx context.Context, jql string, req PageRequest, fn func(jira.Issue) error) error {
+	return paginate(req, func(r PageRequest) (*IssuePage, error) {
+		return s.client.SearchIssues(ctx, jql, r)
+	}, fn)
+}
+
+// searchAll collects every issue matching the JQL query across all pages
+func (s *JiraService) searchAll(ctx context.Context, jql string, req PageRequest) ([]jira.Issue, error) {
+	var issues []jira.Issue
+	err := s.searchIssues(ctx, jql, req, func(issue jira.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// sprintIssues returns every issue of the sprint matching the extra JQL and
+// narrow across all result pages, with the given expansions, e.g.
+// "changelog"
+func (s *JiraService) sprintIssues(ctx context.Context, sprintID int, narrow jql.Clause, expand string) ([]jira.Issue, error) {
+	var issues []jira.Issue
+	err := paginate(PageRequest{JQL: jql.And(narrow, s.extraJQL).String(), Expand: expand}, func(r PageRequest) (*IssuePage, error) {
+		return s.client.GetSprintIssues(ctx, sprintID, r)
+	}, func(issue jira.Issue) error {
+		issues = append(issues, issue)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// boardSprints returns every sprint of the board. With a cache configured,
+// closed sprints come from the cache and only active and future sprints are
+// fetched. The cache is refreshed once a sprint it saw open has closed, an
+// open sprint is newer than every cached one, or Jira lists closed sprints
+// past the cached ones, as sprints created and closed since the cache was
+// stored are missing from it.
+func (s *JiraService) boardSprints(ctx context.Context, boardID int) ([]jira.Sprint, error) {
+	if s.cache == nil {
+		return s.fetchBoardSprints(ctx, boardID, "")
+	}
+
+	cacheKey := fmt.Sprintf("sprints-%d", boardID)
+	var cached []jira.Sprint
+	if _, ok := s.cacheGet(cacheKey, s.cacheTTL.ClosedSprints, &cached); ok {
+		open, err := s.fetchBoardSprints(ctx, boardID, "active,future")
+		if err != nil {
+			return nil, err
+		}
+
+		openIDs := make(map[int]struct{}, len(open))
+		for _, sprint := range open {
+			openIDs[sprint.ID] = struct{}{}
+		}
+
+		stale := false
+		latest := 0
+		var sprints []jira.Sprint
+		for _, sprint := range cached {
+			latest = max(latest, sprint.ID)
+			if sprint.State == "closed" {
+				sprints = append(sprints, sprint)
+			} else if _, ok := openIDs[sprint.ID]; !ok {
+				stale = true
+				break
+			}
+		}
+		for _, sprint := range open {
+			if sprint.ID > latest {
+				stale = true
+			}
+		}
+		if !stale {
+			grew, err := s.closedSprintsGrew(ctx, boardID, sprints)
+			if err != nil {
+				return nil, err
+			}
+			stale = grew
+		}
+		if !stale {
+			return append(sprints, open...), nil
+		}
+		s.cacheDelete(cacheKey)
+	}
+
+	sprints, err := s.fetchBoardSprints(ctx, boardID, "")
+	if err != nil {
+		return nil, err
+	}
+	s.cacheSet(cacheKey, sprints)
+	return sprints, nil
+}
+
+// closedSprintsGrew reports whether the board has closed sprints the cached
+// ones lack. Jira lists sprints in a stable order, so the page starting at
+// the last cached closed sprint holds only that sprint unless sprints were
+// closed since, which either follow it or shift it.
+func (s *JiraService) closedSprintsGrew(ctx context.Context, boardID int, closed []jira.Sprint) (bool, error) {
+	list, err := s.client.GetSprints(ctx, boardID, &jira.GetAllSprintsOptions{
+		State:         "closed",
+		SearchOptions: jira.SearchOptions{StartAt: max(len(closed)-1, 0), MaxResults: 2},
+	})
+	if err != nil {
+		return false, err
+	}
+	if len(closed) == 0 {
+		return len(list.Values) > 0, nil
+	}
+	return len(list.Values) != 1 || list.Values[0].ID != closed[len(closed)-1].ID, nil
+}
+
+// fetchBoardSprints returns the sprints of the board in the given states
+// (comma separated, all states if empty), following startAt/isLast
+func (s *JiraService) fetchBoardSprints(ctx context.Context, boardID int, state string) ([]jira.Sprint, error) {
+	var sprints []jira.Sprint
+	startAt := 0
+	for {
+		list, err := s.client.GetSprints(ctx, boardID, &jira.GetAllSprintsOptions{
+			State:         state,
+			SearchOptions: jira.SearchOptions{StartAt: startAt, MaxResults: pageSize},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		sprints = append(sprints, list.Values...)
+
+		startAt += len(list.Values)
+		if len(list.Values) == 0 || list.IsLast {
+			return sprints, nil
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package jiraservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// serverPageLimit is the page size the fake server caps requests at, as
+// Jira Cloud does
+const serverPageLimit = 50
+
+// fakeJiraServer serves numbered issues and sprints page by page
+type fakeJiraServer struct {
+	issues  int
+	sprints int
+	// gone makes the classic search endpoint answer HTTP 410
+	gone bool
+	// sprintKeys are the issue keys of each sprint, by ID, served after
+	// the sprint's delay. Sprints in failing answer HTTP 500.
+	sprintKeys map[int][]string
+	delays     map[int]time.Duration
+	failing    map[int]bool
+
+	mu    sync.Mutex
+	paths []string
+	// inFlight is the number of requests being served, maxInFlight the
+	// most there have been at once
+	inFlight, maxInFlight int
+}
+
+func (f *fakeJiraServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.paths = append(f.paths, r.URL.Path)
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	q := r.URL.Query()
+	startAt, _ := strconv.Atoi(q.Get("startAt"))
+	limit, _ := strconv.Atoi(q.Get("maxResults"))
+	if limit <= 0 || limit > serverPageLimit {
+		limit = serverPageLimit
+	}
+
+	var sprintID int
+	if _, err := fmt.Sscanf(r.URL.Path, "/rest/agile/1.0/sprint/%d/issue", &sprintID); err == nil && f.sprintKeys != nil {
+		time.Sleep(f.delays[sprintID])
+		if f.failing[sprintID] {
+			http.Error(w, `{"errorMessages":["sprint failed"]}`, http.StatusInternalServerError)
+			return
+		}
+		issues := []map[string]any{}
+		for _, key := range f.sprintKeys[sprintID] {
+			issues = append(issues, map[string]any{"key": key, "fields": map[string]any{"summary": key}})
+		}
+		writeJSON(w, map[string]any{"startAt": 0, "maxResults": limit, "total": len(issues), "issues": issues})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/rest/api/2/search", "/rest/agile/1.0/sprint/7/issue":
+		if f.gone && r.URL.Path == "/rest/api/2/search" {
+			http.Error(w, `{"errorMessages":["gone"]}`, http.StatusGone)
+			return
+		}
+		writeJSON(w, map[string]any{
+			"startAt":    startAt,
+			"maxResults": limit,
+			"total":      f.issues,
+			"issues":     f.issueRange(startAt, limit),
+		})
+
+	case "/rest/api/2/search/jql":
+		if q.Get("fields") == "" {
+			// Without fields the endpoint only returns issue IDs
+			http.Error(w, `{"errorMessages":["no fields"]}`, http.StatusBadRequest)
+			return
+		}
+		start, _ := strconv.Atoi(q.Get("nextPageToken"))
+		page := map[string]any{"issues": f.issueRange(start, limit), "isLast": start+limit >= f.issues}
+		if start+limit < f.issues {
+			page["nextPageToken"] = strconv.Itoa(start + limit)
+		}
+		writeJSON(w, page)
+
+	case "/rest/agile/1.0/board/1/sprint":
+		var values []map[string]any
+		for i := startAt; i < min(startAt+limit, f.sprints); i++ {
+			values = append(values, map[string]any{"id": i + 1, "name": fmt.Sprintf("Sprint %d", i+1), "state": "closed"})
+		}
+		writeJSON(w, map[string]any{
+			"startAt":    startAt,
+			"maxResults": limit,
+			"isLast":     startAt+limit >= f.sprints,
+			"values":     values,
+		})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// issueRange returns the issues PROJ-<start+1> onwards, at most limit
+func (f *fakeJiraServer) issueRange(start, limit int) []map[string]any {
+	issues := []map[string]any{}
+	for i := start; i < min(start+limit, f.issues); i++ {
+		issues = append(issues, map[string]any{
+			"key":    fmt.Sprintf("PROJ-%d", i+1),
+			"fields": map[string]any{"summary": fmt.Sprintf("Issue %d", i+1)},
+		})
+	}
+	return issues
+}
+
+// count returns how many requests went to the path
+func (f *fakeJiraServer) count(path string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, p := range f.paths {
+		if p == path {
+			n++
+		}
+	}
+	return n
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// newTestService returns a service talking to the fake server over HTTP
+func newTestService(t *testing.T, f *fakeJiraServer, opts ...Option) *JiraService {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	client, err := jira.NewClient(srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return NewJiraServiceWithClient(NewHTTPClient(client), srv.URL, "", "", opts...)
+}
+
+// checkKeys fails unless the issues are PROJ-1 to PROJ-n in order
+func checkKeys(t *testing.T, issues []jira.Issue, n int) {
+	t.Helper()
+	if len(issues) != n {
+		t.Fatalf("got %d issues, want %d", len(issues), n)
+	}
+	for i, issue := range issues {
+		if want := fmt.Sprintf("PROJ-%d", i+1); issue.Key != want {
+			t.Fatalf("issue %d is %s, want %s", i, issue.Key, want)
+		}
+	}
+}
+
+func TestSearchAllFollowsStartAtWhenPagesAreCapped(t *testing.T) {
+	f := &fakeJiraServer{issues: 120}
+	s := newTestService(t, f)
+
+	issues, err := s.searchAll(context.Background(), "project = PROJ", PageRequest{})
+	if err != nil {
+		t.Fatalf("searchAll: %v", err)
+	}
+	checkKeys(t, issues, 120)
+	if got := f.count("/rest/api/2/search"); got != 3 {
+		t.Errorf("got %d search requests, want 3", got)
+	}
+}
+
+func TestSearchAllSwitchesToNextPageTokenOnGone(t *testing.T) {
+	f := &fakeJiraServer{issues: 120, gone: true}
+	s := newTestService(t, f)
+
+	issues, err := s.searchAll(context.Background(), "project = PROJ", PageRequest{})
+	if err != nil {
+		t.Fatalf("searchAll: %v", err)
+	}
+	checkKeys(t, issues, 120)
+	if got := f.count("/rest/api/2/search"); got != 1 {
+		t.Errorf("got %d classic search requests, want 1", got)
+	}
+	if got := f.count("/rest/api/2/search/jql"); got != 3 {
+		t.Errorf("got %d token search requests, want 3", got)
+	}
+
+	// The switch holds for the rest of the session
+	if _, err := s.searchAll(context.Background(), "project = PROJ", PageRequest{}); err != nil {
+		t.Fatalf("second searchAll: %v", err)
+	}
+	if got := f.count("/rest/api/2/search"); got != 1 {
+		t.Errorf("got %d classic search requests after the switch, want 1", got)
+	}
+}
+
+func TestSprintIssuesPaging(t *testing.T) {
+	f := &fakeJiraServer{issues: 75}
+	s := newTestService(t, f)
+
+	issues, err := s.sprintIssues(context.Background(), 7, jql.Clause{}, "")
+	if err != nil {
+		t.Fatalf("sprintIssues: %v", err)
+	}
+	checkKeys(t, issues, 75)
+	if got := f.count("/rest/agile/1.0/sprint/7/issue"); got != 2 {
+		t.Errorf("got %d sprint issue requests, want 2", got)
+	}
+}
+
+func TestBoardSprintsPaging(t *testing.T) {
+	f := &fakeJiraServer{sprints: 130}
+	s := newTestService(t, f)
+
+	sprints, err := s.boardSprints(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("boardSprints: %v", err)
+	}
+	if len(sprints) != 130 {
+		t.Fatalf("got %d sprints, want 130", len(sprints))
+	}
+	for i, sprint := range sprints {
+		if sprint.ID != i+1 {
+			t.Fatalf("sprint %d has ID %d, want %d", i, sprint.ID, i+1)
+		}
+	}
+	if got := f.count("/rest/agile/1.0/board/1/sprint"); got != 3 {
+		t.Errorf("got %d sprint requests, want 3", got)
+	}
+}
+
+func TestPaginateStopsOnShortOrEmptyPages(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []IssuePage
+		want  int
+	}{
+		{
+			name:  "total reached",
+			pages: []IssuePage{{Issues: make([]jira.Issue, 50), Total: 60}, {Issues: make([]jira.Issue, 10), StartAt: 50, Total: 60}},
+			want:  60,
+		},
+		{
+			name:  "empty page before total",
+			pages: []IssuePage{{Issues: make([]jira.Issue, 50), Total: 200}, {StartAt: 50, Total: 200}},
+			want:  50,
+		},
+		{
+			name:  "last page flagged",
+			pages: []IssuePage{{Issues: make([]jira.Issue, 5), NextPageToken: "a"}, {Issues: make([]jira.Issue, 5), IsLast: true}},
+			want:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, got := 0, 0
+			err := paginate(PageRequest{}, func(req PageRequest) (*IssuePage, error) {
+				if calls >= len(tt.pages) {
+					t.Fatalf("requested page %d of %d", calls+1, len(tt.pages))
+				}
+				page := tt.pages[calls]
+				calls++
+				return &page, nil
+			}, func(jira.Issue) error {
+				got++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("paginate: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %d issues, want %d", got, tt.want)
+			}
+		})
+	}
+}
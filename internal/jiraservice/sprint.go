@@ -0,0 +1,196 @@
+package jiraservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// Sprint selectors accepted besides IDs and names
+const (
+	SprintActive     = "active"
+	SprintLastClosed = "last-closed"
+)
+
+// maxSprintSuggestions caps the close matches listed when no sprint matches
+const maxSprintSuggestions = 5
+
+// FindSprintWithContext returns the sprint of the board picked by the
+// selector: "active", "last-closed", a sprint ID, or a name. Names are
+// matched exactly, then ignoring case, then by prefix; when nothing
+// matches the error lists the closest sprint names.
+func (s *JiraService) FindSprintWithContext(ctx context.Context, boardName, selector string) (*jira.Sprint, error) {
+	board, err := s.GetBoardWithContext(ctx, boardName)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Found board '%s' with ID %d", boardName, board.ID)
+
+	sprints, err := s.boardSprints(ctx, board.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprints: %w", err)
+	}
+	log.Printf("Found %d sprints for board '%s'", len(sprints), boardName)
+
+	sprint, err := selectSprint(sprints, selector)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Found sprint '%s' with ID %d", sprint.Name, sprint.ID)
+	return sprint, nil
+}
+
+// selectSprint picks the sprint matching the selector
+func selectSprint(sprints []jira.Sprint, selector string) (*jira.Sprint, error) {
+	selector = strings.TrimSpace(selector)
+	if selector == "" {
+		return nil, errors.New("no sprint given")
+	}
+
+	switch strings.ToLower(selector) {
+	case SprintActive:
+		var active []jira.Sprint
+		for _, sprint := range sprints {
+			if sprint.State == "active" {
+				active = append(active, sprint)
+			}
+		}
+		switch len(active) {
+		case 0:
+			return nil, errors.New("board has no active sprint")
+		case 1:
+			return &active[0], nil
+		}
+		return nil, fmt.Errorf("board has %d active sprints, pick one: %s", len(active), sprintNames(active))
+	case SprintLastClosed:
+		var last *jira.Sprint
+		for i, sprint := range sprints {
+			if sprint.State == "closed" && (last == nil || sprintClosedAt(sprint).After(sprintClosedAt(*last))) {
+				last = &sprints[i]
+			}
+		}
+		if last == nil {
+			return nil, errors.New("board has no closed sprint")
+		}
+		return last, nil
+	}
+
+	if id, err := strconv.Atoi(selector); err == nil {
+		for i, sprint := range sprints {
+			if sprint.ID == id {
+				return &sprints[i], nil
+			}
+		}
+	}
+
+	// Sprints sharing a name are ambiguous even when matched exactly, the
+	// error lists their IDs to pick from
+	var exact, folded, prefixed []jira.Sprint
+	lower := strings.ToLower(selector)
+	for _, sprint := range sprints {
+		name := strings.ToLower(sprint.Name)
+		switch {
+		case sprint.Name == selector:
+			exact = append(exact, sprint)
+		case name == lower:
+			folded = append(folded, sprint)
+		case strings.HasPrefix(name, lower):
+			prefixed = append(prefixed, sprint)
+		}
+	}
+	for _, matches := range [][]jira.Sprint{exact, folded, prefixed} {
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return &matches[0], nil
+		}
+		return nil, fmt.Errorf("sprint '%s' is ambiguous, it matches %s", selector, sprintNames(matches))
+	}
+
+	if similar := closeSprints(sprints, selector); len(similar) > 0 {
+		return nil, fmt.Errorf("sprint '%s' not found, did you mean %s?", selector, sprintNames(similar))
+	}
+	return nil, fmt.Errorf("sprint '%s' not found", selector)
+}
+
+// sprintClosedAt returns when the sprint was completed, or else its end
+func sprintClosedAt(sprint jira.Sprint) time.Time {
+	switch {
+	case sprint.CompleteDate != nil:
+		return *sprint.CompleteDate
+	case sprint.EndDate != nil:
+		return *sprint.EndDate
+	}
+	return time.Time{}
+}
+
+// closeSprints returns the sprints whose names contain the selector or are
+// within a few edits of it, closest first
+func closeSprints(sprints []jira.Sprint, selector string) []jira.Sprint {
+	lower := strings.ToLower(selector)
+	maxDistance := len(lower)/3 + 1
+
+	type candidate struct {
+		sprint   jira.Sprint
+		distance int
+	}
+	var candidates []candidate
+	for _, sprint := range sprints {
+		name := strings.ToLower(sprint.Name)
+		distance := editDistance(name, lower)
+		if strings.Contains(name, lower) || distance <= maxDistance {
+			candidates = append(candidates, candidate{sprint, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var similar []jira.Sprint
+	for _, c := range candidates {
+		if len(similar) == maxSprintSuggestions {
+			break
+		}
+		similar = append(similar, c.sprint)
+	}
+	return similar
+}
+
+// sprintNames lists sprint names for error messages
+func sprintNames(sprints []jira.Sprint) string {
+	names := make([]string, 0, len(sprints))
+	for _, sprint := range sprints {
+		names = append(names, fmt.Sprintf("'%s' (%d)", sprint.Name, sprint.ID))
+	}
+	return strings.Join(names, ", ")
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,82 @@
+package jiraservice
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+func TestSelectSprint(t *testing.T) {
+	closedAt := func(day int) *time.Time {
+		t := time.Date(2025, time.September, day, 8, 0, 0, 0, time.UTC)
+		return &t
+	}
+	sprints := []jira.Sprint{
+		{ID: 1, Name: "Sprint 1", State: "closed", CompleteDate: closedAt(1)},
+		{ID: 10, Name: "Sprint 10", State: "closed", CompleteDate: closedAt(29)},
+		{ID: 11, Name: "Sprint 11", State: "closed", EndDate: closedAt(15)},
+		{ID: 12, Name: "Sprint 12", State: "active"},
+		{ID: 13, Name: "Sprint 13", State: "future"},
+		{ID: 20, Name: "Hardening", State: "closed", CompleteDate: closedAt(2)},
+		{ID: 21, Name: "Hardening", State: "future"},
+		{ID: 30, Name: "Release Prep", State: "future"},
+		{ID: 31, Name: "release prep", State: "future"},
+		{ID: 40, Name: "7", State: "future"},
+		{ID: 41, Name: "12", State: "future"},
+		{ID: 50, Name: "Retro week", State: "future"},
+	}
+	noneClosed := []jira.Sprint{{ID: 1, Name: "Sprint 1", State: "active"}, {ID: 2, Name: "Sprint 2", State: "future"}}
+	twoActive := []jira.Sprint{{ID: 1, Name: "Team A", State: "active"}, {ID: 2, Name: "Team B", State: "active"}}
+
+	tests := []struct {
+		name     string
+		sprints  []jira.Sprint
+		selector string
+		// wantID is the sprint picked, wantErr a part of the error otherwise
+		wantID  int
+		wantErr string
+	}{
+		{name: "active", sprints: sprints, selector: "active", wantID: 12},
+		{name: "active ignores case", sprints: sprints, selector: " Active ", wantID: 12},
+		{name: "no active sprint", sprints: sprints[:3], selector: "active", wantErr: "no active sprint"},
+		{name: "several active sprints", sprints: twoActive, selector: "active", wantErr: "2 active sprints"},
+		{name: "last closed by completion or end date", sprints: sprints, selector: "last-closed", wantID: 10},
+		{name: "last closed when nothing is closed", sprints: noneClosed, selector: "last-closed", wantErr: "no closed sprint"},
+		{name: "ID", sprints: sprints, selector: "11", wantID: 11},
+		{name: "ID wins over name", sprints: sprints, selector: "12", wantID: 12},
+		{name: "number that is no ID matches names", sprints: sprints, selector: "7", wantID: 40},
+		{name: "exact name wins over prefix", sprints: sprints, selector: "Sprint 1", wantID: 1},
+		{name: "name ignoring case", sprints: sprints, selector: "sprint 13", wantID: 13},
+		{name: "exact name wins over case", sprints: sprints, selector: "release prep", wantID: 31},
+		{name: "name ambiguous ignoring case", sprints: sprints, selector: "RELEASE PREP", wantErr: "'Release Prep' (30), 'release prep' (31)"},
+		{name: "two sprints with the same name", sprints: sprints, selector: "Hardening", wantErr: "'Hardening' (20), 'Hardening' (21)"},
+		{name: "prefix", sprints: sprints, selector: "retro", wantID: 50},
+		{name: "prefix of several sprints", sprints: sprints, selector: "Release", wantErr: "ambiguous"},
+		{name: "suggestion", sprints: sprints, selector: "Sprnt 13", wantErr: "did you mean 'Sprint 13' (13)"},
+		{name: "nothing close", sprints: sprints, selector: "Quarterly planning", wantErr: "sprint 'Quarterly planning' not found"},
+		{name: "empty", sprints: sprints, selector: "  ", wantErr: "no sprint given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sprint, err := selectSprint(tt.sprints, tt.selector)
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("got sprint %d, want an error containing %q", sprint.ID, tt.wantErr)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %q, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectSprint: %v", err)
+			}
+			if sprint.ID != tt.wantID {
+				t.Errorf("got sprint %d, want %d", sprint.ID, tt.wantID)
+			}
+		})
+	}
+}
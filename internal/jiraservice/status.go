@@ -0,0 +1,178 @@
+package jiraservice
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jql"
+)
+
+// StatusCategory is the workflow stage a status belongs to, identified by
+// Jira's status category key
+type StatusCategory string
+
+const (
+	StatusToDo       StatusCategory = jira.StatusCategoryToDo
+	StatusInProgress StatusCategory = jira.StatusCategoryInProgress
+	StatusDone       StatusCategory = jira.StatusCategoryComplete
+)
+
+// defaultStatusCategories classify the usual status names when neither the
+// configuration nor Jira says otherwise
+var defaultStatusCategories = map[string]StatusCategory{
+	"in progress": StatusInProgress,
+	"done":        StatusDone,
+	"closed":      StatusDone,
+	"resolved":    StatusDone,
+}
+
+// statusCategories classifies statuses by name. Configured statuses win,
+// then the category Jira reports for the status, then the defaults.
+type statusCategories struct {
+	// configured maps lower-cased status names to their category. When a
+	// category has configured statuses, no other status belongs to it.
+	configured map[string]StatusCategory
+	exclusive  map[StatusCategory]struct{}
+
+	mu       sync.Mutex
+	fromJira map[string]StatusCategory
+	loaded   bool
+}
+
+func newStatusCategories(active, done []string) *statusCategories {
+	c := &statusCategories{
+		configured: make(map[string]StatusCategory),
+		exclusive:  make(map[StatusCategory]struct{}),
+		fromJira:   make(map[string]StatusCategory),
+	}
+	c.configure(active, StatusInProgress)
+	c.configure(done, StatusDone)
+	return c
+}
+
+func (c *statusCategories) configure(statuses []string, category StatusCategory) {
+	for name := range createFilterMap(statuses) {
+		c.configured[name] = category
+		c.exclusive[category] = struct{}{}
+	}
+}
+
+// configuredNames returns the configured statuses of the category, sorted
+func (c *statusCategories) configuredNames(category StatusCategory) []string {
+	var names []string
+	for name, cat := range c.configured {
+		if cat == category {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// names returns the statuses of the category: the configured ones, or else
+// those Jira puts in it. The defaults are only used when Jira's statuses are
+// unknown, as Jira rejects queries naming statuses it does not have.
+func (c *statusCategories) names(category StatusCategory) []string {
+	if _, ok := c.exclusive[category]; ok {
+		return c.configuredNames(category)
+	}
+
+	c.mu.Lock()
+	known := c.fromJira
+	if len(known) == 0 {
+		known = defaultStatusCategories
+	}
+	seen := make(map[string]struct{})
+	for name, cat := range known {
+		if cat == category {
+			seen[name] = struct{}{}
+		}
+	}
+	c.mu.Unlock()
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		if _, ok := c.configured[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Category returns the category of the status
+func (c *statusCategories) Category(status string) StatusCategory {
+	key := strings.ToLower(strings.TrimSpace(status))
+	if category, ok := c.configured[key]; ok {
+		return category
+	}
+
+	c.mu.Lock()
+	category, ok := c.fromJira[key]
+	c.mu.Unlock()
+	if !ok {
+		category, ok = defaultStatusCategories[key]
+	}
+	if !ok {
+		return StatusToDo
+	}
+
+	// Configured statuses replace Jira's for their category
+	if _, ok := c.exclusive[category]; ok {
+		return StatusToDo
+	}
+	return category
+}
+
+// learn records the category Jira reports for a status
+func (c *statusCategories) learn(status jira.Status) {
+	switch category := StatusCategory(status.StatusCategory.Key); category {
+	case StatusToDo, StatusInProgress, StatusDone:
+		c.mu.Lock()
+		c.fromJira[strings.ToLower(strings.TrimSpace(status.Name))] = category
+		c.mu.Unlock()
+	}
+}
+
+// loadStatusCategories fetches the categories of all statuses once, so
+// statuses only seen in changelogs are classified the way Jira does. When
+// they cannot be fetched the defaults apply.
+func (s *JiraService) loadStatusCategories(ctx context.Context) error {
+	s.statuses.mu.Lock()
+	loaded := s.statuses.loaded
+	s.statuses.mu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	statuses, err := s.client.GetStatuses(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		log.Printf("warning: failed to get status categories, using defaults: %v", err)
+	}
+	for _, status := range statuses {
+		s.statuses.learn(status)
+	}
+
+	s.statuses.mu.Lock()
+	s.statuses.loaded = true
+	s.statuses.mu.Unlock()
+	return nil
+}
+
+// activeStatusClause returns the JQL condition matching issues currently in
+// an in-progress status
+func (s *JiraService) activeStatusClause() jql.Clause {
+	names := s.statuses.configuredNames(StatusInProgress)
+	if len(names) == 0 {
+		return jql.Eq("statusCategory", jql.String("In Progress"))
+	}
+	return jql.In("status", jql.Strings(names)...)
+}
@@ -0,0 +1,61 @@
+package jiraservice
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+func TestStatusNamesUseDefaultsOnlyWithoutJiraStatuses(t *testing.T) {
+	inDevelopment := jira.Status{Name: "In Development", StatusCategory: jira.StatusCategory{Key: jira.StatusCategoryInProgress}}
+	shipped := jira.Status{Name: "Shipped", StatusCategory: jira.StatusCategory{Key: jira.StatusCategoryComplete}}
+
+	tests := []struct {
+		name     string
+		statuses []jira.Status
+		active   []string
+		done     []string
+	}{
+		{
+			name:     "statuses from Jira",
+			statuses: []jira.Status{inDevelopment, shipped},
+			active:   []string{"in development"},
+			done:     []string{"shipped"},
+		},
+		{
+			name:   "no statuses from Jira",
+			active: []string{"in progress"},
+			done:   []string{"closed", "done", "resolved"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewJiraServiceWithClient(&FakeClient{Statuses: tt.statuses}, "https://jira.example.com", "", "")
+			if err := s.loadStatusCategories(context.Background()); err != nil {
+				t.Fatalf("loadStatusCategories: %v", err)
+			}
+
+			if got := s.statuses.names(StatusInProgress); !reflect.DeepEqual(got, tt.active) {
+				t.Errorf("got in progress statuses %v, want %v", got, tt.active)
+			}
+			if got := s.statuses.names(StatusDone); !reflect.DeepEqual(got, tt.done) {
+				t.Errorf("got done statuses %v, want %v", got, tt.done)
+			}
+		})
+	}
+}
+
+func TestConfiguredStatusNames(t *testing.T) {
+	s := NewJiraServiceWithClient(&FakeClient{}, "https://jira.example.com", "", "",
+		WithActiveStatuses([]string{"Doing", "Review"}))
+
+	if got, want := s.statuses.names(StatusInProgress), []string{"doing", "review"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := s.statuses.Category("In Progress"); got != StatusToDo {
+		t.Errorf("got category %s for an unconfigured status, want %s", got, StatusToDo)
+	}
+}
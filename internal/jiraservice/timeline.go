@@ -0,0 +1,173 @@
+package jiraservice
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// StatusInterval is a period an issue spent in one status
+type StatusInterval struct {
+	Status string
+	From   time.Time
+	// To is zero while the issue is still in the status
+	To time.Time
+}
+
+// Duration returns how long the interval lasted, up to now when it is open
+func (i StatusInterval) Duration(now time.Time) time.Duration {
+	if i.To.IsZero() {
+		return now.Sub(i.From)
+	}
+	return i.To.Sub(i.From)
+}
+
+// Overlaps reports whether the interval overlaps the range [start, end)
+func (i StatusInterval) Overlaps(start, end time.Time) bool {
+	if !i.From.Before(end) {
+		return false
+	}
+	return i.To.IsZero() || i.To.After(start)
+}
+
+// WasInCategoryDuring reports whether the issue spent any time in a status
+// of the category within [start, end)
+func WasInCategoryDuring(timeline []StatusInterval, categoryOf func(status string) StatusCategory, category StatusCategory, start, end time.Time) bool {
+	for _, interval := range timeline {
+		if categoryOf(interval.Status) == category && interval.Overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// FlowMetrics describes how an issue moved through the workflow
+type FlowMetrics struct {
+	// TimeInStatus is the total time spent in each status. Time in the
+	// done status an issue is still in is not counted.
+	TimeInStatus map[string]time.Duration
+	// Started is when the issue first entered an in-progress status, zero
+	// if never
+	Started time.Time
+	// Done is when the issue last entered a done status, zero if it is
+	// not done now
+	Done time.Time
+	// CycleTime runs from Started to Done and LeadTime from creation to
+	// Done; both are zero until the issue is done
+	CycleTime time.Duration
+	LeadTime  time.Duration
+}
+
+// statusChange is a status transition read from the changelog
+type statusChange struct {
+	at       time.Time
+	from, to string
+}
+
+// parseChangelogTime parses the timestamp of a changelog entry
+func parseChangelogTime(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02T15:04:05.000-0700", s)
+	if err != nil {
+		// Try alternate format
+		t, err = time.Parse(time.RFC3339, s)
+	}
+	return t, err
+}
+
+// statusChanges returns the status transitions of the changelog, oldest first
+func statusChanges(changelog *jira.Changelog) []statusChange {
+	if changelog == nil {
+		return nil
+	}
+
+	var changes []statusChange
+	for _, history := range changelog.Histories {
+		at, err := parseChangelogTime(history.Created)
+		if err != nil {
+			log.Printf("warning: could not parse changelog timestamp %s: %v", history.Created, err)
+			continue
+		}
+		for _, item := range history.Items {
+			if item.Field == "status" {
+				changes = append(changes, statusChange{at: at, from: item.FromString, to: item.ToString})
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].at.Before(changes[j].at)
+	})
+	return changes
+}
+
+// BuildStatusTimeline reconstructs the statuses an issue went through from
+// its creation, given its current status. The last interval is open.
+func BuildStatusTimeline(created time.Time, currentStatus string, changelog *jira.Changelog) []StatusInterval {
+	changes := statusChanges(changelog)
+
+	// The status before the first transition is the one it was created in
+	status := currentStatus
+	if len(changes) > 0 {
+		status = changes[0].from
+	}
+	from := created
+	if from.IsZero() && len(changes) > 0 {
+		from = changes[0].at
+	}
+	if from.IsZero() {
+		return nil
+	}
+
+	var timeline []StatusInterval
+	for _, change := range changes {
+		if change.at.Before(from) {
+			// Changes dated before creation (imports, moved issues) only
+			// update the status
+			status = change.to
+			continue
+		}
+		timeline = append(timeline, StatusInterval{Status: status, From: from, To: change.at})
+		status, from = change.to, change.at
+	}
+	return append(timeline, StatusInterval{Status: status, From: from})
+}
+
+// ComputeFlowMetrics derives time-in-status, cycle time and lead time from
+// a status timeline, counting open intervals up to now
+func ComputeFlowMetrics(created time.Time, timeline []StatusInterval, categoryOf func(status string) StatusCategory, now time.Time) FlowMetrics {
+	m := FlowMetrics{TimeInStatus: make(map[string]time.Duration)}
+
+	wasDone := false
+	for _, interval := range timeline {
+		category := categoryOf(interval.Status)
+		done := category == StatusDone
+		if !done || !interval.To.IsZero() {
+			m.TimeInStatus[interval.Status] += interval.Duration(now)
+		}
+
+		if category == StatusInProgress && m.Started.IsZero() {
+			m.Started = interval.From
+		}
+		// Reopened issues are done again only when they are done at the end
+		if done && !wasDone {
+			m.Done = interval.From
+		}
+		wasDone = done
+	}
+
+	if !wasDone || len(timeline) < 2 {
+		// Not done, or created done and never moved
+		m.Done = time.Time{}
+		return m
+	}
+
+	if !m.Started.IsZero() {
+		m.CycleTime = m.Done.Sub(m.Started)
+	}
+	if !created.IsZero() {
+		m.LeadTime = m.Done.Sub(created)
+	}
+	return m
+}
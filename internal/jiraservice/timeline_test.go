@@ -0,0 +1,182 @@
+package jiraservice
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+)
+
+// transition is a status change at an instant, for building changelogs
+type transition struct {
+	at       time.Time
+	from, to string
+}
+
+// changelogOf returns a changelog with a history per transition
+func changelogOf(transitions ...transition) *jira.Changelog {
+	changelog := &jira.Changelog{}
+	for _, tr := range transitions {
+		changelog.Histories = append(changelog.Histories, jira.ChangelogHistory{
+			Created: tr.at.Format("2006-01-02T15:04:05.000-0700"),
+			Items:   []jira.ChangelogItems{{Field: "status", FromString: tr.from, ToString: tr.to}},
+		})
+	}
+	return changelog
+}
+
+// day returns midnight UTC of the day in 2025
+func day(month time.Month, d int) time.Time {
+	return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// testCategory classifies the statuses used in the tests
+func testCategory(status string) StatusCategory {
+	switch status {
+	case "In Progress":
+		return StatusInProgress
+	case "Done":
+		return StatusDone
+	}
+	return StatusToDo
+}
+
+func TestStatusTimelineActiveDuringRange(t *testing.T) {
+	start, end := day(time.October, 1), day(time.November, 1)
+
+	tests := []struct {
+		name      string
+		created   time.Time
+		current   string
+		changelog *jira.Changelog
+		// statuses are the expected statuses of the timeline, in order
+		statuses []string
+		active   bool
+	}{
+		{
+			name:      "started before the range and still in progress",
+			created:   day(time.September, 1),
+			current:   "In Progress",
+			changelog: changelogOf(transition{day(time.September, 15), "Open", "In Progress"}),
+			statuses:  []string{"Open", "In Progress"},
+			active:    true,
+		},
+		{
+			name:    "started before the range and done inside it",
+			created: day(time.September, 1),
+			current: "Done",
+			changelog: changelogOf(
+				transition{day(time.September, 15), "Open", "In Progress"},
+				transition{day(time.October, 3), "In Progress", "Done"}),
+			statuses: []string{"Open", "In Progress", "Done"},
+			active:   true,
+		},
+		{
+			name:    "done before the range",
+			created: day(time.September, 1),
+			current: "Done",
+			changelog: changelogOf(
+				transition{day(time.September, 15), "Open", "In Progress"},
+				transition{day(time.September, 20), "In Progress", "Done"}),
+			statuses: []string{"Open", "In Progress", "Done"},
+			active:   false,
+		},
+		{
+			name:      "entered exactly at the end",
+			created:   day(time.September, 1),
+			current:   "In Progress",
+			changelog: changelogOf(transition{end, "Open", "In Progress"}),
+			statuses:  []string{"Open", "In Progress"},
+			active:    false,
+		},
+		{
+			name:    "left exactly at the start",
+			created: day(time.September, 1),
+			current: "Done",
+			changelog: changelogOf(
+				transition{day(time.September, 15), "Open", "In Progress"},
+				transition{start, "In Progress", "Done"}),
+			statuses: []string{"Open", "In Progress", "Done"},
+			active:   false,
+		},
+		{
+			name:    "reopened inside the range",
+			created: day(time.September, 1),
+			current: "In Progress",
+			changelog: changelogOf(
+				transition{day(time.September, 2), "Open", "In Progress"},
+				transition{day(time.September, 5), "In Progress", "Done"},
+				transition{day(time.October, 10), "Done", "In Progress"}),
+			statuses: []string{"Open", "In Progress", "Done", "In Progress"},
+			active:   true,
+		},
+		{
+			name:    "reopened after the range",
+			created: day(time.September, 1),
+			current: "In Progress",
+			changelog: changelogOf(
+				transition{day(time.September, 2), "Open", "In Progress"},
+				transition{day(time.September, 5), "In Progress", "Done"},
+				transition{day(time.November, 5), "Done", "In Progress"}),
+			statuses: []string{"Open", "In Progress", "Done", "In Progress"},
+			active:   false,
+		},
+		{
+			name:      "change dated before creation",
+			created:   day(time.October, 15),
+			current:   "In Progress",
+			changelog: changelogOf(transition{day(time.October, 10), "Open", "In Progress"}),
+			statuses:  []string{"In Progress"},
+			active:    true,
+		},
+		{
+			name:      "done before creation",
+			created:   day(time.October, 15),
+			current:   "Done",
+			changelog: changelogOf(transition{day(time.August, 1), "In Progress", "Done"}),
+			statuses:  []string{"Done"},
+			active:    false,
+		},
+		{
+			name:     "missing changelog in progress",
+			created:  day(time.October, 5),
+			current:  "In Progress",
+			statuses: []string{"In Progress"},
+			active:   true,
+		},
+		{
+			name:     "missing changelog to do",
+			created:  day(time.October, 5),
+			current:  "Open",
+			statuses: []string{"Open"},
+			active:   false,
+		},
+		{
+			name:    "missing changelog and creation date",
+			current: "In Progress",
+			active:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline := BuildStatusTimeline(tt.created, tt.current, tt.changelog)
+
+			var statuses []string
+			for i, interval := range timeline {
+				statuses = append(statuses, interval.Status)
+				if last := i == len(timeline)-1; last != interval.To.IsZero() {
+					t.Errorf("interval %d is open %v, want only the last one open", i, interval.To.IsZero())
+				}
+			}
+			if !reflect.DeepEqual(statuses, tt.statuses) {
+				t.Errorf("got statuses %v, want %v", statuses, tt.statuses)
+			}
+
+			if got := WasInCategoryDuring(timeline, testCategory, StatusInProgress, start, end); got != tt.active {
+				t.Errorf("got in progress during the range %v, want %v", got, tt.active)
+			}
+		})
+	}
+}
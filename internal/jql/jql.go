@@ -0,0 +1,318 @@
+// Package jql builds Jira Query Language queries with every value quoted and
+// escaped, so keys, names and user input cannot change a query's meaning.
+package jql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Value is an operand of a clause, already rendered as JQL
+type Value string
+
+// String quotes a text value
+func String(s string) Value {
+	return Value(quote(s))
+}
+
+// Strings quotes each text value
+func Strings(values []string) []Value {
+	out := make([]Value, 0, len(values))
+	for _, v := range values {
+		out = append(out, String(v))
+	}
+	return out
+}
+
+// Date is a day, e.g. "2025-10-01". Jira reads it in the Jira user's time
+// zone.
+func Date(t time.Time) Value {
+	return Value(quote(t.Format("2006-01-02")))
+}
+
+// DateTime is a minute, e.g. "2025-10-01 14:30", in the Jira user's time
+// zone
+func DateTime(t time.Time) Value {
+	return Value(quote(t.Format("2006-01-02 15:04")))
+}
+
+// MinutesAgo is a date relative to now, e.g. -90m
+func MinutesAgo(minutes int) Value {
+	return Value(fmt.Sprintf("-%dm", minutes))
+}
+
+// Number is a numeric value
+func Number(n int) Value {
+	return Value(fmt.Sprintf("%d", n))
+}
+
+// Func calls a JQL function, e.g. Func("startOfMonth") or
+// Func("membersOf", String("team-a"))
+func Func(name string, args ...Value) Value {
+	parts := make([]string, 0, len(args))
+	for _, a := range args {
+		parts = append(parts, string(a))
+	}
+	return Value(fmt.Sprintf("%s(%s)", name, strings.Join(parts, ", ")))
+}
+
+// clauseKind tells how a clause must be wrapped when it is combined
+type clauseKind int
+
+const (
+	kindSimple clauseKind = iota
+	kindAnd
+	kindOr
+	kindRaw
+)
+
+// Clause is a JQL condition. The zero Clause matches everything and is
+// dropped from And and Or.
+type Clause struct {
+	text string
+	kind clauseKind
+}
+
+// IsZero reports whether the clause is empty
+func (c Clause) IsZero() bool {
+	return c.text == ""
+}
+
+// String returns the clause as JQL
+func (c Clause) String() string {
+	return c.text
+}
+
+// Compare is "field op value", e.g. Compare("created", ">=", Date(t))
+func Compare(field, op string, value Value) Clause {
+	return Clause{text: fmt.Sprintf("%s %s %s", Field(field), op, value)}
+}
+
+// Eq is "field = value"
+func Eq(field string, value Value) Clause {
+	return Compare(field, "=", value)
+}
+
+// In is "field in (values)". With no values the clause is empty, so
+// callers must not rely on In to exclude everything.
+func In(field string, values ...Value) Clause {
+	if len(values) == 0 {
+		return Clause{}
+	}
+	return Clause{text: fmt.Sprintf("%s in (%s)", Field(field), join(values))}
+}
+
+// WasInDuring is "field WAS IN (values) DURING (from, to)", matching issues
+// whose field had one of the values at any time in the range
+func WasInDuring(field string, values []Value, from, to Value) Clause {
+	if len(values) == 0 {
+		return Clause{}
+	}
+	return Clause{text: fmt.Sprintf("%s WAS IN (%s) DURING (%s, %s)", Field(field), join(values), from, to)}
+}
+
+// And joins the clauses with AND
+func And(clauses ...Clause) Clause {
+	return combine(kindAnd, " AND ", clauses)
+}
+
+// Or joins the clauses with OR
+func Or(clauses ...Clause) Clause {
+	return combine(kindOr, " OR ", clauses)
+}
+
+func combine(kind clauseKind, sep string, clauses []Clause) Clause {
+	var parts []string
+	var last Clause
+	for _, c := range clauses {
+		if c.IsZero() {
+			continue
+		}
+		last = c
+		// Everything but simple clauses and same-operator groups is
+		// parenthesised, so precedence never depends on the input
+		if c.kind != kindSimple && c.kind != kind {
+			parts = append(parts, "("+c.text+")")
+		} else {
+			parts = append(parts, c.text)
+		}
+	}
+	switch len(parts) {
+	case 0:
+		return Clause{}
+	case 1:
+		return last
+	}
+	return Clause{text: strings.Join(parts, sep), kind: kind}
+}
+
+// Raw accepts a JQL condition written by a user, e.g. from a command line
+// flag. A trailing ORDER BY is dropped. The condition is always
+// parenthesised when combined, and is rejected when its quotes or
+// parentheses are unbalanced, as that could escape the parentheses.
+func Raw(s string) (Clause, error) {
+	where, _, err := Split(s)
+	if err != nil {
+		return Clause{}, err
+	}
+	return Clause{text: where, kind: kindRaw}, nil
+}
+
+// Split separates a user query into its condition and ORDER BY list,
+// checking that quotes and parentheses are balanced
+func Split(s string) (where, orderBy string, err error) {
+	s = strings.TrimSpace(s)
+
+	depth := 0
+	var inQuote rune
+	orderAt := -1
+	for i, r := range s {
+		switch {
+		case inQuote != 0:
+			if r == '\\' {
+				continue
+			}
+			if r == inQuote && !escaped(s, i) {
+				inQuote = 0
+			}
+		case r == '"' || r == '\'':
+			inQuote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return "", "", errors.New("unbalanced parentheses in JQL")
+			}
+		default:
+			if depth == 0 && orderAt < 0 && orderByAt.MatchString(s[i:]) && (i == 0 || isSpace(s[i-1])) {
+				orderAt = i
+			}
+		}
+	}
+	if inQuote != 0 {
+		return "", "", errors.New("unterminated quote in JQL")
+	}
+	if depth != 0 {
+		return "", "", errors.New("unbalanced parentheses in JQL")
+	}
+
+	if orderAt < 0 {
+		return s, "", nil
+	}
+	orderBy = strings.TrimSpace(orderByAt.ReplaceAllString(s[orderAt:], ""))
+	return strings.TrimSpace(s[:orderAt]), orderBy, nil
+}
+
+var orderByAt = regexp.MustCompile(`(?i)^ORDER\s+BY\s+`)
+
+// Order is one ORDER BY term
+type Order struct {
+	Field string
+	Desc  bool
+}
+
+// Query is a complete JQL query: a condition and its ordering
+type Query struct {
+	Where Clause
+	Order []Order
+	// RawOrder is an ORDER BY list taken verbatim from a user query; it is
+	// used when Order is empty
+	RawOrder string
+}
+
+// String returns the query as JQL
+func (q Query) String() string {
+	var terms []string
+	for _, o := range q.Order {
+		term := Field(o.Field)
+		if o.Desc {
+			term += " DESC"
+		}
+		terms = append(terms, term)
+	}
+
+	order := strings.Join(terms, ", ")
+	if order == "" {
+		order = q.RawOrder
+	}
+
+	switch {
+	case order == "":
+		return q.Where.String()
+	case q.Where.IsZero():
+		return "ORDER BY " + order
+	}
+	return q.Where.String() + " ORDER BY " + order
+}
+
+var plainField = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*|cf\[\d+\])$`)
+
+// reservedWords are the words Jira rejects unquoted in a query
+var reservedWords = toSet(strings.Fields(`
+	a an abort access add after alias all alter and any are as asc at audit avg
+	before begin between boolean break by byte catch cf char character check
+	checkpoint collate collation column commit connect continue count create
+	current date decimal declare decrement default defaults define delete
+	delimiter desc difference distinct divide do double drop else empty
+	encoding end equals escape exclusive exec execute exists explain false
+	fetch file field first float for from function go goto grant greater group
+	having identified if immediate in increment index initial inner inout input
+	insert int integer intersect intersection into is isempty isnull join last
+	left less like limit lock long max min minus mode modify modulo more
+	multiply next noaudit not notin nowait null number object of on option or
+	order outer output power previous prior privileges public raise raw
+	remainder rename resource return returns revoke right row rowid rownum rows
+	select session set share size sqrt start strict string subtract sum synonym
+	table then to trans transaction trigger true uid union unique update user
+	validate values view when whenever where while with`))
+
+// Field renders a field name, quoting names that are not plain identifiers,
+// e.g. "Epic Link", and reserved words
+func Field(name string) string {
+	if plainField.MatchString(name) {
+		if _, reserved := reservedWords[strings.ToLower(name)]; !reserved {
+			return name
+		}
+	}
+	return quote(name)
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func join(values []Value) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, string(v))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// escaped reports whether the character at i is preceded by an odd number
+// of backslashes
+func escaped(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func toSet(words []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		set[w] = struct{}{}
+	}
+	return set
+}
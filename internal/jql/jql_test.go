@@ -0,0 +1,199 @@
+package jql
+
+import (
+	"testing"
+	"time"
+)
+
+// mustRaw returns the user condition, failing the test if it is rejected
+func mustRaw(t *testing.T, s string) Clause {
+	t.Helper()
+	clause, err := Raw(s)
+	if err != nil {
+		t.Fatalf("Raw(%q): %v", s, err)
+	}
+	return clause
+}
+
+func TestValues(t *testing.T) {
+	day := time.Date(2025, time.October, 1, 14, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value Value
+		want  string
+	}{
+		{name: "plain", value: String("PROJ"), want: `"PROJ"`},
+		{name: "double quote", value: String(`say "hi"`), want: `"say \"hi\""`},
+		{name: "single quote", value: String("it's"), want: `"it's"`},
+		{name: "backslash", value: String(`C:\temp`), want: `"C:\\temp"`},
+		{name: "backslash before quote", value: String(`a\"b`), want: `"a\\\"b"`},
+		{name: "trailing backslash", value: String(`a\`), want: `"a\\"`},
+		{name: "JQL inside", value: String(`x" OR project = OTHER`), want: `"x\" OR project = OTHER"`},
+		{name: "reserved word", value: String("order"), want: `"order"`},
+		{name: "empty", value: String(""), want: `""`},
+		{name: "date", value: Date(day), want: `"2025-10-01"`},
+		{name: "date time", value: DateTime(day), want: `"2025-10-01 14:30"`},
+		{name: "minutes ago", value: MinutesAgo(90), want: `-90m`},
+		{name: "number", value: Number(42), want: `42`},
+		{name: "function", value: Func("startOfMonth"), want: `startOfMonth()`},
+		{name: "function with arguments", value: Func("membersOf", String("team a")), want: `membersOf("team a")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.value); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "project", want: `project`},
+		{name: "customfield_10014", want: `customfield_10014`},
+		{name: "cf[10014]", want: `cf[10014]`},
+		{name: "Epic Link", want: `"Epic Link"`},
+		{name: "Story-Points", want: `"Story-Points"`},
+		{name: "order", want: `"order"`},
+		{name: "AND", want: `"AND"`},
+		{name: "Empty", want: `"Empty"`},
+		{name: `say "hi"`, want: `"say \"hi\""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Field(tt.name); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClauses(t *testing.T) {
+	project := Eq("project", String("PROJ"))
+	types := In("issuetype", Strings([]string{"Bug", "Task"})...)
+	user := mustRaw(t, "component = API OR labels = backend")
+
+	tests := []struct {
+		name   string
+		clause Clause
+		want   string
+	}{
+		{name: "compare", clause: Compare("created", ">=", String("2025-10-01")), want: `created >= "2025-10-01"`},
+		{name: "quoted field", clause: Eq("Epic Link", String("PROJ-1")), want: `"Epic Link" = "PROJ-1"`},
+		{name: "in", clause: types, want: `issuetype in ("Bug", "Task")`},
+		{name: "empty in", clause: In("issuetype"), want: ``},
+		{name: "was in during", clause: WasInDuring("status", Strings([]string{"In Progress"}), String("2025-10-01"), String("2025-11-01")),
+			want: `status WAS IN ("In Progress") DURING ("2025-10-01", "2025-11-01")`},
+		{name: "empty was in during", clause: WasInDuring("status", nil, String("a"), String("b")), want: ``},
+		{name: "and", clause: And(project, types), want: `project = "PROJ" AND issuetype in ("Bug", "Task")`},
+		{name: "and drops empty clauses", clause: And(Clause{}, project, In("key"), Clause{}), want: `project = "PROJ"`},
+		{name: "and of nothing", clause: And(Clause{}, In("key")), want: ``},
+		{name: "or", clause: Or(project, types), want: `project = "PROJ" OR issuetype in ("Bug", "Task")`},
+		{name: "or inside and", clause: And(project, Or(types, Eq("key", String("PROJ-1")))),
+			want: `project = "PROJ" AND (issuetype in ("Bug", "Task") OR key = "PROJ-1")`},
+		{name: "and inside or", clause: Or(And(project, types), Eq("key", String("PROJ-1"))),
+			want: `(project = "PROJ" AND issuetype in ("Bug", "Task")) OR key = "PROJ-1"`},
+		{name: "nested and is flattened", clause: And(And(project, types), Eq("key", String("PROJ-1"))),
+			want: `project = "PROJ" AND issuetype in ("Bug", "Task") AND key = "PROJ-1"`},
+		{name: "user JQL with OR stays grouped", clause: And(project, user),
+			want: `project = "PROJ" AND (component = API OR labels = backend)`},
+		{name: "user JQL grouped in OR too", clause: Or(project, user),
+			want: `project = "PROJ" OR (component = API OR labels = backend)`},
+		{name: "user JQL alone", clause: And(Clause{}, user), want: `component = API OR labels = backend`},
+		{name: "user JQL ORDER BY dropped", clause: And(project, mustRaw(t, "labels = x ORDER BY key")),
+			want: `project = "PROJ" AND (labels = x)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.clause.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if tt.clause.IsZero() != (tt.want == "") {
+				t.Errorf("got IsZero %v for %q", tt.clause.IsZero(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		where   string
+		orderBy string
+		wantErr bool
+	}{
+		{name: "condition only", query: " project = PROJ ", where: "project = PROJ"},
+		{name: "order by", query: "project = PROJ ORDER BY key DESC", where: "project = PROJ", orderBy: "key DESC"},
+		{name: "lower case order by", query: "project = PROJ order  by created", where: "project = PROJ", orderBy: "created"},
+		{name: "order by only", query: "ORDER BY key", orderBy: "key"},
+		{name: "order by in quotes", query: `summary ~ "order by" ORDER BY key`, where: `summary ~ "order by"`, orderBy: "key"},
+		{name: "order by in parentheses", query: `(summary ~ x ORDER BY key)`, where: `(summary ~ x ORDER BY key)`},
+		{name: "field ending in order", query: `reorder by = 1`, where: `reorder by = 1`},
+		{name: "escaped quote", query: `summary ~ "a \" b" ORDER BY key`, where: `summary ~ "a \" b"`, orderBy: "key"},
+		{name: "escaped backslash before quote", query: `summary ~ "a\\" AND key = X`, where: `summary ~ "a\\" AND key = X`},
+		{name: "single quotes", query: `summary ~ 'it''s'`, where: `summary ~ 'it''s'`},
+		{name: "unterminated quote", query: `summary ~ "abc`, wantErr: true},
+		{name: "quote escaped to the end", query: `summary ~ "abc\"`, wantErr: true},
+		{name: "unbalanced open", query: `(project = PROJ`, wantErr: true},
+		{name: "unbalanced close", query: `project = PROJ) OR (key = X`, wantErr: true},
+		{name: "parenthesis in quotes", query: `summary ~ ")"`, where: `summary ~ ")"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, orderBy, err := Split(tt.query)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, %q, want an error", where, orderBy)
+				}
+				if _, err := Raw(tt.query); err == nil {
+					t.Errorf("Raw accepted %q", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Split: %v", err)
+			}
+			if where != tt.where || orderBy != tt.orderBy {
+				t.Errorf("got %q, %q, want %q, %q", where, orderBy, tt.where, tt.orderBy)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	where := Eq("project", String("PROJ"))
+
+	tests := []struct {
+		name  string
+		query Query
+		want  string
+	}{
+		{name: "condition only", query: Query{Where: where}, want: `project = "PROJ"`},
+		{name: "order", query: Query{Where: where, Order: []Order{{Field: "rank"}, {Field: "created", Desc: true}}},
+			want: `project = "PROJ" ORDER BY rank, created DESC`},
+		{name: "quoted order field", query: Query{Where: where, Order: []Order{{Field: "Epic Link"}}},
+			want: `project = "PROJ" ORDER BY "Epic Link"`},
+		{name: "raw order", query: Query{Where: where, RawOrder: "key DESC"}, want: `project = "PROJ" ORDER BY key DESC`},
+		{name: "order wins over raw order", query: Query{Where: where, Order: []Order{{Field: "key"}}, RawOrder: "created"},
+			want: `project = "PROJ" ORDER BY key`},
+		{name: "order only", query: Query{Order: []Order{{Field: "key"}}}, want: `ORDER BY key`},
+		{name: "empty", query: Query{}, want: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.String(); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-word-create/internal/config"
+	"go-word-create/internal/jiraservice"
+)
+
+// dateFormat is how dates are shown in report tables
+const dateFormat = "2006-01-02"
+
+// Column is an extra report table column
+type Column struct {
+	Header string
+	// Left aligns the cells left instead of centering them
+	Left  bool
+	Value func(issue jiraservice.Issue) string
+}
+
+// Columns are the extra columns appended to the standard ones of a table
+type Columns []Column
+
+// optionalColumns are the columns that can be selected by name
+var optionalColumns = map[string]Column{
+	"assignee":   {Header: "Assignee", Value: func(i jiraservice.Issue) string { return i.Assignee }},
+	"reporter":   {Header: "Reporter", Value: func(i jiraservice.Issue) string { return i.Reporter }},
+	"priority":   {Header: "Priority", Value: func(i jiraservice.Issue) string { return i.Priority }},
+	"resolution": {Header: "Resolution", Value: func(i jiraservice.Issue) string { return i.Resolution }},
+	"labels": {Header: "Labels", Left: true, Value: func(i jiraservice.Issue) string {
+		return strings.Join(i.Labels, ", ")
+	}},
+	"components": {Header: "Components", Left: true, Value: func(i jiraservice.Issue) string {
+		return strings.Join(i.Components, ", ")
+	}},
+	"fixversions": {Header: "Fix Versions", Value: func(i jiraservice.Issue) string {
+		return strings.Join(i.FixVersions, ", ")
+	}},
+	"created":  {Header: "Created", Value: func(i jiraservice.Issue) string { return formatDate(i.Created) }},
+	"updated":  {Header: "Updated", Value: func(i jiraservice.Issue) string { return formatDate(i.Updated) }},
+	"resolved": {Header: "Resolved", Value: func(i jiraservice.Issue) string { return formatDate(i.Resolved) }},
+}
+
+// ColumnNames returns the names accepted by ParseColumns
+func ColumnNames() []string {
+	names := make([]string, 0, len(optionalColumns))
+	for name := range optionalColumns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseColumns parses a comma separated list of optional column names,
+// e.g. "assignee,priority,resolved", keeping the given order
+func ParseColumns(list string) (Columns, error) {
+	var columns Columns
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		column, ok := optionalColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown column '%s', available columns: %s", name, strings.Join(ColumnNames(), ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// CustomFieldColumns returns one column per configured custom field
+func CustomFieldColumns(fields []config.CustomField) Columns {
+	var columns Columns
+	for _, f := range fields {
+		name := f.Name
+		columns = append(columns, Column{Header: name, Value: func(i jiraservice.Issue) string {
+			return i.Custom[name]
+		}})
+	}
+	return columns
+}
+
+// Headers returns the headers of the columns
+func (cs Columns) Headers() []string {
+	headers := make([]string, 0, len(cs))
+	for _, c := range cs {
+		headers = append(headers, c.Header)
+	}
+	return headers
+}
+
+// Values returns the cell values of the columns for the issue
+func (cs Columns) Values(issue jiraservice.Issue) []string {
+	values := make([]string, 0, len(cs))
+	for _, c := range cs {
+		values = append(values, c.Value(issue))
+	}
+	return values
+}
+
+// LeftAligned returns the table indexes of the left aligned columns, when
+// the columns start at index offset
+func (cs Columns) LeftAligned(offset int) []int {
+	var indexes []int
+	for i, c := range cs {
+		if c.Left {
+			indexes = append(indexes, offset+i)
+		}
+	}
+	return indexes
+}
+
+// formatDate formats a date for a table cell, empty when unset
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateFormat)
+}
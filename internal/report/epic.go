@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go-word-create/internal/jiraservice"
+)
+
+// progressBarWidth is the number of blocks of a progress bar
+const progressBarWidth = 10
+
+// EpicSummary is the progress of an epic, measured on its child issues
+type EpicSummary struct {
+	Key    string
+	Name   string
+	Issues int
+	Done   int
+	// DonePoints and RemainingPoints split the story points of the children
+	// by whether they are done
+	DonePoints      float64
+	RemainingPoints float64
+	// Started is when the first child went in progress, LastActivity when
+	// a child was last updated; zero when unknown
+	Started      time.Time
+	LastActivity time.Time
+}
+
+// SummarizeEpic computes the progress of the epic from its children
+func SummarizeEpic(epic jiraservice.EpicProgress) EpicSummary {
+	summary := EpicSummary{Key: epic.Key, Name: epic.Name, Issues: len(epic.Children)}
+	for _, issue := range epic.Children {
+		if issue.StatusCategory == jiraservice.StatusDone {
+			summary.Done++
+			summary.DonePoints += issue.StoryPoints
+		} else {
+			summary.RemainingPoints += issue.StoryPoints
+		}
+
+		if started := issue.Flow.Started; !started.IsZero() && (summary.Started.IsZero() || started.Before(summary.Started)) {
+			summary.Started = started
+		}
+		activity := issue.Updated
+		if n := len(issue.Timeline); n > 0 && issue.Timeline[n-1].From.After(activity) {
+			activity = issue.Timeline[n-1].From
+		}
+		if activity.After(summary.LastActivity) {
+			summary.LastActivity = activity
+		}
+	}
+	return summary
+}
+
+// Complete returns the done share of the epic, by story points or, when its
+// children are not estimated, by issue count
+func (e EpicSummary) Complete() float64 {
+	if total := e.DonePoints + e.RemainingPoints; total > 0 {
+		return e.DonePoints / total
+	}
+	if e.Issues > 0 {
+		return float64(e.Done) / float64(e.Issues)
+	}
+	return 0
+}
+
+// Heading names the epic with its completion, e.g. "PROJ-1 Reporting (45%)"
+func (e EpicSummary) Heading() string {
+	return fmt.Sprintf("%s %s (%.0f%%)", e.Key, e.Name, e.Complete()*100)
+}
+
+// ProgressBar draws the fraction as a bar of blocks for a table cell, e.g.
+// "██████░░░░"
+func ProgressBar(fraction float64) string {
+	filled := int(math.Round(math.Max(0, math.Min(1, fraction)) * progressBarWidth))
+	return strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+}
+
+// FormatDate formats a date for a table cell, empty when unset
+func FormatDate(t time.Time) string {
+	return formatDate(t)
+}
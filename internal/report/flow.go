@@ -0,0 +1,123 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-word-create/internal/jiraservice"
+)
+
+// FlowSummary aggregates the flow metrics of the completed issues
+type FlowSummary struct {
+	// Completed is the number of completed issues. LeadCount and
+	// CycleCount are the number of them with a lead time, and with a cycle
+	// time as they went through In Progress.
+	Completed   int
+	LeadCount   int
+	CycleCount  int
+	AvgCycle    time.Duration
+	MedianCycle time.Duration
+	AvgLead     time.Duration
+	MedianLead  time.Duration
+}
+
+// CompletedIssues returns the issues that reached a done status
+func CompletedIssues(issues []jiraservice.Issue) []jiraservice.Issue {
+	var completed []jiraservice.Issue
+	for _, issue := range issues {
+		if !issue.Flow.Done.IsZero() {
+			completed = append(completed, issue)
+		}
+	}
+	return completed
+}
+
+// SummarizeFlow computes the average and median cycle and lead times of the
+// completed issues. Issues that never went through In Progress have no
+// cycle time, and issues done the instant they were created no lead time;
+// both are still counted as completed.
+func SummarizeFlow(issues []jiraservice.Issue) FlowSummary {
+	completed := CompletedIssues(issues)
+	var cycle, lead []time.Duration
+	for _, issue := range completed {
+		if issue.Flow.CycleTime > 0 {
+			cycle = append(cycle, issue.Flow.CycleTime)
+		}
+		if issue.Flow.LeadTime > 0 {
+			lead = append(lead, issue.Flow.LeadTime)
+		}
+	}
+
+	summary := FlowSummary{Completed: len(completed), LeadCount: len(lead), CycleCount: len(cycle)}
+	summary.AvgCycle, summary.MedianCycle = averageAndMedian(cycle)
+	summary.AvgLead, summary.MedianLead = averageAndMedian(lead)
+	return summary
+}
+
+// String describes the summary in a sentence for the report
+func (f FlowSummary) String() string {
+	if f.Completed == 0 {
+		return "No issues were completed."
+	}
+	text := fmt.Sprintf("%d completed issues.", f.Completed)
+	if f.LeadCount > 0 {
+		text += fmt.Sprintf(" Lead time: average %s, median %s.", FormatDays(f.AvgLead), FormatDays(f.MedianLead))
+	}
+	if f.CycleCount > 0 {
+		text += fmt.Sprintf(" Cycle time: average %s, median %s.", FormatDays(f.AvgCycle), FormatDays(f.MedianCycle))
+	}
+	return text
+}
+
+// FormatDays formats a duration in days with one decimal, e.g. "3.5d"
+func FormatDays(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+// FormatTimeInStatus lists the time spent in each status, longest first,
+// e.g. "In Progress 4.0d, Review 1.5d"
+func FormatTimeInStatus(timeInStatus map[string]time.Duration) string {
+	statuses := make([]string, 0, len(timeInStatus))
+	for status := range timeInStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		a, b := timeInStatus[statuses[i]], timeInStatus[statuses[j]]
+		if a != b {
+			return a > b
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	parts := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		if days := FormatDays(timeInStatus[status]); days != "" {
+			parts = append(parts, status+" "+days)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func averageAndMedian(values []time.Duration) (time.Duration, time.Duration) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, v := range sorted {
+		total += v
+	}
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return total / time.Duration(len(sorted)), median
+}
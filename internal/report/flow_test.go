@@ -0,0 +1,62 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"go-word-create/internal/jiraservice"
+)
+
+func TestSummarizeFlow(t *testing.T) {
+	day := 24 * time.Hour
+	done := time.Date(2025, time.October, 10, 9, 0, 0, 0, time.UTC)
+	issue := func(cycle, lead time.Duration) jiraservice.Issue {
+		return jiraservice.Issue{Flow: jiraservice.FlowMetrics{Done: done, CycleTime: cycle, LeadTime: lead}}
+	}
+	open := jiraservice.Issue{Flow: jiraservice.FlowMetrics{CycleTime: 2 * day, LeadTime: 3 * day}}
+
+	tests := []struct {
+		name   string
+		issues []jiraservice.Issue
+		want   FlowSummary
+		text   string
+	}{
+		{name: "nothing completed", issues: []jiraservice.Issue{open}, text: "No issues were completed."},
+		{
+			name:   "cycle and lead times",
+			issues: []jiraservice.Issue{issue(day, 2*day), issue(3*day, 4*day), open},
+			want:   FlowSummary{Completed: 2, LeadCount: 2, CycleCount: 2, AvgCycle: 2 * day, MedianCycle: 2 * day, AvgLead: 3 * day, MedianLead: 3 * day},
+			text:   "2 completed issues. Lead time: average 3.0d, median 3.0d. Cycle time: average 2.0d, median 2.0d.",
+		},
+		{
+			name:   "never in progress",
+			issues: []jiraservice.Issue{issue(0, 2*day), issue(day, 4*day)},
+			want:   FlowSummary{Completed: 2, LeadCount: 2, CycleCount: 1, AvgCycle: day, MedianCycle: day, AvgLead: 3 * day, MedianLead: 3 * day},
+			text:   "2 completed issues. Lead time: average 3.0d, median 3.0d. Cycle time: average 1.0d, median 1.0d.",
+		},
+		{
+			name:   "done the instant it was created",
+			issues: []jiraservice.Issue{issue(0, 0), issue(0, 2*day)},
+			want:   FlowSummary{Completed: 2, LeadCount: 1, AvgLead: 2 * day, MedianLead: 2 * day},
+			text:   "2 completed issues. Lead time: average 2.0d, median 2.0d.",
+		},
+		{
+			name:   "no lead time at all",
+			issues: []jiraservice.Issue{issue(0, 0)},
+			want:   FlowSummary{Completed: 1},
+			text:   "1 completed issues.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SummarizeFlow(tt.issues)
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.text {
+				t.Errorf("got %q, want %q", got.String(), tt.text)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PeriodKind is how a reporting period was selected
+type PeriodKind int
+
+const (
+	PeriodMonth PeriodKind = iota
+	PeriodWeek
+	PeriodQuarter
+	PeriodCustom
+)
+
+// Period is a reporting time range. End is exclusive.
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time
+	End   time.Time
+}
+
+// PeriodFlags holds the raw values of the period selection flags
+type PeriodFlags struct {
+	// Month is "YYYY.MM"
+	Month string
+	// Week is an ISO week, "YYYY-Www"
+	Week string
+	// Quarter is "YYYYQn"
+	Quarter string
+	// From and To are "YYYY-MM-DD"; To is inclusive and defaults to today
+	From string
+	To   string
+}
+
+var (
+	weekPattern    = regexp.MustCompile(`^(\d{4})-?W(\d{1,2})$`)
+	quarterPattern = regexp.MustCompile(`^(\d{4})-?Q([1-4])$`)
+)
+
+// ParsePeriod builds the period selected by exactly one of the flags, with
+// its boundaries at midnight in loc
+func ParsePeriod(f PeriodFlags, loc *time.Location) (Period, error) {
+	selected := 0
+	for _, v := range []string{f.Month, f.Week, f.Quarter, f.From} {
+		if v != "" {
+			selected++
+		}
+	}
+	if f.To != "" && f.From == "" {
+		return Period{}, errors.New("-to requires -from")
+	}
+	if selected == 0 {
+		return Period{}, errors.New("one of -month, -week, -quarter or -from is required")
+	}
+	if selected > 1 {
+		return Period{}, errors.New("only one of -month, -week, -quarter or -from can be used")
+	}
+
+	switch {
+	case f.Month != "":
+		return parseMonth(f.Month, loc)
+	case f.Week != "":
+		return parseWeek(f.Week, loc)
+	case f.Quarter != "":
+		return parseQuarter(f.Quarter, loc)
+	default:
+		return parseRange(f.From, f.To, loc)
+	}
+}
+
+func parseMonth(value string, loc *time.Location) (Period, error) {
+	start, err := time.ParseInLocation("2006.01", value, loc)
+	if err != nil {
+		return Period{}, errors.New("invalid month format, use YYYY.MM")
+	}
+	return Period{Kind: PeriodMonth, Start: start, End: start.AddDate(0, 1, 0)}, nil
+}
+
+func parseWeek(value string, loc *time.Location) (Period, error) {
+	m := weekPattern.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil {
+		return Period{}, errors.New("invalid week format, use YYYY-Www, e.g. 2025-W42")
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+
+	// January 4th is always in week 1, weeks start on Monday
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	start := monday.AddDate(0, 0, (week-1)*7)
+
+	if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+		return Period{}, fmt.Errorf("week %d does not exist in %d", week, year)
+	}
+	return Period{Kind: PeriodWeek, Start: start, End: start.AddDate(0, 0, 7)}, nil
+}
+
+func parseQuarter(value string, loc *time.Location) (Period, error) {
+	m := quarterPattern.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil {
+		return Period{}, errors.New("invalid quarter format, use YYYYQn, e.g. 2025Q4")
+	}
+	year, _ := strconv.Atoi(m[1])
+	quarter, _ := strconv.Atoi(m[2])
+
+	start := time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, loc)
+	return Period{Kind: PeriodQuarter, Start: start, End: start.AddDate(0, 3, 0)}, nil
+}
+
+func parseRange(from, to string, loc *time.Location) (Period, error) {
+	start, err := time.ParseInLocation("2006-01-02", from, loc)
+	if err != nil {
+		return Period{}, errors.New("invalid -from date, use YYYY-MM-DD")
+	}
+
+	var last time.Time
+	if to == "" {
+		now := time.Now().In(loc)
+		last = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	} else if last, err = time.ParseInLocation("2006-01-02", to, loc); err != nil {
+		return Period{}, errors.New("invalid -to date, use YYYY-MM-DD")
+	}
+
+	if last.Before(start) {
+		return Period{}, errors.New("-to is before -from")
+	}
+	return Period{Kind: PeriodCustom, Start: start, End: last.AddDate(0, 0, 1)}, nil
+}
+
+// Label names the period for headings, e.g. "October 2025", "week 42 of
+// 2025", "Q4 2025" or "2025-10-01 - 2025-10-15"
+func (p Period) Label() string {
+	switch p.Kind {
+	case PeriodMonth:
+		return p.Start.Format("January 2006")
+	case PeriodWeek:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("week %d of %d", week, year)
+	case PeriodQuarter:
+		return fmt.Sprintf("Q%d %d", (int(p.Start.Month())-1)/3+1, p.Start.Year())
+	default:
+		return fmt.Sprintf("%s - %s", p.Start.Format("2006-01-02"), p.lastDay().Format("2006-01-02"))
+	}
+}
+
+// FileSuffix identifies the period in file names, e.g. "2025-10",
+// "2025-W42", "2025-Q4" or "2025-10-01_2025-10-15"
+func (p Period) FileSuffix() string {
+	switch p.Kind {
+	case PeriodMonth:
+		return p.Start.Format("2006-01")
+	case PeriodWeek:
+		year, week := p.Start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", p.Start.Year(), (int(p.Start.Month())-1)/3+1)
+	default:
+		return fmt.Sprintf("%s_%s", p.Start.Format("2006-01-02"), p.lastDay().Format("2006-01-02"))
+	}
+}
+
+// lastDay returns the last day within the period
+func (p Period) lastDay() time.Time {
+	return p.End.AddDate(0, 0, -1)
+}
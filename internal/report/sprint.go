@@ -0,0 +1,54 @@
+package report
+
+import (
+	"fmt"
+	"strconv"
+
+	"go-word-create/internal/jiraservice"
+)
+
+// StoryPoints sums the story points of the issues
+func StoryPoints(issues []jiraservice.Issue) float64 {
+	total := 0.0
+	for _, issue := range issues {
+		total += issue.StoryPoints
+	}
+	return total
+}
+
+// ScopeSection is one group of issues of the sprint scope report
+type ScopeSection struct {
+	Title  string
+	Issues []jiraservice.Issue
+}
+
+// Heading names the section with its issue count and story points, e.g.
+// "Added (2 issues, 4.0 SP)"
+func (s ScopeSection) Heading() string {
+	return fmt.Sprintf("%s (%d issues, %.1f SP)", s.Title, len(s.Issues), StoryPoints(s.Issues))
+}
+
+// ScopeSections returns the groups of the sprint scope in report order.
+// Issues carried over to the next sprint are only known once the sprint is
+// closed.
+func ScopeSections(scope *jiraservice.SprintScope) []ScopeSection {
+	sections := []ScopeSection{
+		{Title: "Committed", Issues: scope.Committed},
+		{Title: "Added", Issues: scope.Added},
+		{Title: "Removed", Issues: scope.Removed},
+		{Title: "Carried over from previous sprints", Issues: scope.CarriedIn},
+	}
+	if scope.Sprint.State == "closed" {
+		sections = append(sections, ScopeSection{Title: "Carried over to the next sprint", Issues: scope.CarriedOver})
+	}
+	return sections
+}
+
+// SprintsColumn shows how many sprints an issue has been in, see
+// jiraservice.AddSprintHistory
+var SprintsColumn = Column{Header: "Sprints", Value: func(i jiraservice.Issue) string {
+	if len(i.Sprints) == 0 {
+		return ""
+	}
+	return strconv.Itoa(len(i.Sprints))
+}}
@@ -0,0 +1,139 @@
+package report
+
+import (
+	"fmt"
+	"math"
+
+	"go-word-create/internal/jiraservice"
+)
+
+// SprintVelocity is what a closed sprint committed to and completed
+type SprintVelocity struct {
+	Sprint          string
+	CommittedPoints float64
+	CompletedPoints float64
+	CommittedIssues int
+	CompletedIssues int
+	// RollingAverage is the mean completed points of this sprint and the
+	// ones before it within the window
+	RollingAverage float64
+}
+
+// VelocitySummary aggregates the velocity of the sprints
+type VelocitySummary struct {
+	Sprints         int
+	AvgCommitted    float64
+	StdDevCommitted float64
+	AvgCompleted    float64
+	StdDevCompleted float64
+	// CompletionRate is the share of committed points completed overall
+	CompletionRate float64
+}
+
+// Velocities returns the velocity of each sprint scope, in order. The
+// commitment is what was in the sprint at its start, estimated as it was
+// then; completed issues are those in the sprint at its end that were not
+// carried over, estimated as they were at the end.
+func Velocities(scopes []jiraservice.SprintScope, window int) []SprintVelocity {
+	velocities := make([]SprintVelocity, 0, len(scopes))
+	for _, scope := range scopes {
+		completed := completedIssues(scope)
+		velocities = append(velocities, SprintVelocity{
+			Sprint:          scope.Sprint.Name,
+			CommittedPoints: storyPointsAt(scope.Initial, scope.StartPoints),
+			CompletedPoints: storyPointsAt(completed, scope.EndPoints),
+			CommittedIssues: len(scope.Initial),
+			CompletedIssues: len(completed),
+		})
+	}
+
+	if window < 1 {
+		window = 1
+	}
+	for i := range velocities {
+		from := max(0, i-window+1)
+		total := 0.0
+		for _, v := range velocities[from : i+1] {
+			total += v.CompletedPoints
+		}
+		velocities[i].RollingAverage = total / float64(i+1-from)
+	}
+	return velocities
+}
+
+// storyPointsAt sums the story points of the issues as recorded in points,
+// falling back to an issue's current estimate when it has no entry
+func storyPointsAt(issues []jiraservice.Issue, points map[string]float64) float64 {
+	total := 0.0
+	for _, issue := range issues {
+		if sp, ok := points[issue.Key]; ok {
+			total += sp
+		} else {
+			total += issue.StoryPoints
+		}
+	}
+	return total
+}
+
+// completedIssues returns the issues of the scope still in the sprint at its
+// end that were not carried over
+func completedIssues(scope jiraservice.SprintScope) []jiraservice.Issue {
+	carried := make(map[string]struct{}, len(scope.CarriedOver))
+	for _, issue := range scope.CarriedOver {
+		carried[issue.Key] = struct{}{}
+	}
+
+	var completed []jiraservice.Issue
+	for _, group := range [][]jiraservice.Issue{scope.Committed, scope.Added} {
+		for _, issue := range group {
+			if _, ok := carried[issue.Key]; !ok {
+				completed = append(completed, issue)
+			}
+		}
+	}
+	return completed
+}
+
+// SummarizeVelocity computes the mean and standard deviation of the
+// committed and completed points
+func SummarizeVelocity(velocities []SprintVelocity) VelocitySummary {
+	summary := VelocitySummary{Sprints: len(velocities)}
+	if len(velocities) == 0 {
+		return summary
+	}
+
+	committed := make([]float64, 0, len(velocities))
+	completed := make([]float64, 0, len(velocities))
+	for _, v := range velocities {
+		committed = append(committed, v.CommittedPoints)
+		completed = append(completed, v.CompletedPoints)
+	}
+	summary.AvgCommitted, summary.StdDevCommitted = meanAndStdDev(committed)
+	summary.AvgCompleted, summary.StdDevCompleted = meanAndStdDev(completed)
+	if summary.AvgCommitted > 0 {
+		summary.CompletionRate = summary.AvgCompleted / summary.AvgCommitted
+	}
+	return summary
+}
+
+// String renders the summary as a single line for the report
+func (v VelocitySummary) String() string {
+	return fmt.Sprintf("%d sprints: average velocity %.1f SP (standard deviation %.1f), average commitment %.1f SP (standard deviation %.1f), %.0f%% of committed points completed",
+		v.Sprints, v.AvgCompleted, v.StdDevCompleted, v.AvgCommitted, v.StdDevCommitted, v.CompletionRate*100)
+}
+
+// meanAndStdDev returns the mean and population standard deviation of the
+// values
+func meanAndStdDev(values []float64) (float64, float64) {
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	mean := total / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	jira "github.com/andygrunwald/go-jira"
+
+	"go-word-create/internal/jiraservice"
+)
+
+func TestVelocityUsesEstimatesOfTheSprint(t *testing.T) {
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	change := func(t time.Time, field, from, to string) jira.ChangelogHistory {
+		item := jira.ChangelogItems{Field: field, FromString: from, ToString: to}
+		if field == "Sprint" {
+			item.From, item.To = from, to
+		}
+		return jira.ChangelogHistory{Created: t.Format("2006-01-02T15:04:05.000-0700"), Items: []jira.ChangelogItems{item}}
+	}
+
+	start, complete := at(time.September, 1), at(time.September, 15)
+	sprint := jira.Sprint{ID: 1, Name: "Sprint 1", State: "closed", StartDate: &start, CompleteDate: &complete}
+
+	// Estimated 3 at the start, re-estimated to 5 mid-sprint and done, then
+	// re-estimated to 8 after the sprint
+	issue := jira.Issue{
+		Key: "PROJ-1",
+		Fields: &jira.IssueFields{
+			Type:     jira.IssueType{Name: "Story"},
+			Project:  jira.Project{Key: "PROJ"},
+			Status:   &jira.Status{Name: "Done"},
+			Created:  jira.Time(at(time.August, 1)),
+			Unknowns: map[string]interface{}{"customfield_10004": 8.0},
+		},
+		Changelog: &jira.Changelog{Histories: []jira.ChangelogHistory{
+			change(at(time.August, 20), "Sprint", "", "1"),
+			change(at(time.September, 5), "Story Points", "3", "5"),
+			change(at(time.September, 10), "status", "In Progress", "Done"),
+			change(at(time.September, 20), "Story Points", "5", "8"),
+		}},
+	}
+
+	fake := &jiraservice.FakeClient{
+		Sprints:      map[int][]jira.Sprint{1: {sprint}},
+		SprintIssues: map[int][]string{1: {"PROJ-1"}},
+		Issues:       []jira.Issue{issue},
+	}
+	s := jiraservice.NewJiraServiceWithClient(fake, "https://jira.example.com", "", "customfield_10004")
+	scope, err := s.GetSprintScopeWithContext(context.Background(), "PROJ", &sprint, nil)
+	if err != nil {
+		t.Fatalf("GetSprintScope: %v", err)
+	}
+
+	velocities := Velocities([]jiraservice.SprintScope{*scope}, 3)
+	if len(velocities) != 1 {
+		t.Fatalf("got %d velocities, want 1", len(velocities))
+	}
+	v := velocities[0]
+	if v.CommittedPoints != 3 {
+		t.Errorf("got %.1f committed points, want the 3 estimated at the start", v.CommittedPoints)
+	}
+	if v.CompletedPoints != 5 {
+		t.Errorf("got %.1f completed points, want the 5 estimated at the end", v.CompletedPoints)
+	}
+	if v.CommittedIssues != 1 || v.CompletedIssues != 1 {
+		t.Errorf("got %d committed and %d completed issues, want 1 and 1", v.CommittedIssues, v.CompletedIssues)
+	}
+}
@@ -0,0 +1,226 @@
+package word
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/carmel/gooxml"
+	"github.com/carmel/gooxml/chart"
+	"github.com/carmel/gooxml/color"
+	"github.com/carmel/gooxml/measurement"
+	"github.com/carmel/gooxml/schema/soo/dml"
+	crt "github.com/carmel/gooxml/schema/soo/dml/chart"
+	"github.com/carmel/gooxml/schema/soo/wml"
+	"github.com/carmel/gooxml/zippkg"
+)
+
+// chartURI identifies chart references in the graphic data of a drawing
+const chartURI = "http://schemas.openxmlformats.org/drawingml/2006/chart"
+
+// documentRels is the part holding the relationships of the document body
+const documentRels = "word/_rels/document.xml.rels"
+
+// Size of the charts in the document
+const (
+	chartWidth  = 6 * measurement.Inch
+	chartHeight = 3.5 * measurement.Inch
+)
+
+// ChartSeries is a named series of values, one per chart category
+type ChartSeries struct {
+	Name   string
+	Values []float64
+	// Dashed draws the series of a line chart dashed, e.g. a target
+	Dashed bool
+}
+
+// chartPart is a chart of the document together with the relationship the
+// document refers to it by
+type chartPart struct {
+	relID string
+	space *crt.ChartSpace
+}
+
+// AddBarChart adds a column chart with a bar per series in each category
+func (d *Doc) AddBarChart(title string, categories []string, series []ChartSeries) {
+	c := d.addChart(title)
+	bars := c.AddBarChart()
+	addAxes(c, bars)
+
+	for _, s := range series {
+		ser := bars.AddSeries()
+		ser.SetText(s.Name)
+		ser.CategoryAxis().SetValues(categories)
+		ser.Values().SetValues(s.Values)
+	}
+}
+
+// AddLineChart adds a line chart with a line per series across the categories
+func (d *Doc) AddLineChart(title string, categories []string, series []ChartSeries) {
+	c := d.addChart(title)
+	lines := c.AddLineChart()
+	addAxes(c, lines)
+
+	for _, s := range series {
+		ser := lines.AddSeries()
+		ser.SetText(s.Name)
+		ser.SetSmooth(false)
+		line := ser.Properties().LineProperties()
+		line.SetWidth(2 * measurement.Point)
+		if s.Dashed {
+			line.X().PrstDash = dml.NewCT_PresetLineDashProperties()
+			line.X().PrstDash.ValAttr = dml.ST_PresetLineDashValDash
+		}
+		ser.CategoryAxis().SetValues(categories)
+		ser.Values().SetValues(s.Values)
+	}
+}
+
+// addAxes adds the category and value axes the chart plots against
+func addAxes(c chart.Chart, plot interface{ AddAxis(chart.Axis) }) {
+	catAx := c.AddCategoryAxis()
+	valAx := c.AddValueAxis()
+	// Only the values get grid lines
+	for _, ax := range c.X().Chart.PlotArea.CChoice.CatAx {
+		ax.MajorGridlines = nil
+	}
+	catAx.SetCrosses(valAx)
+	valAx.SetCrosses(catAx)
+	plot.AddAxis(catAx)
+	plot.AddAxis(valAx)
+}
+
+// addChart adds an empty chart with a title and a legend in a paragraph of
+// its own. Its part is written when the document is saved.
+func (d *Doc) addChart(title string) chart.Chart {
+	space := crt.NewChartSpace()
+	relID := fmt.Sprintf("rIdChart%d", len(d.charts)+1)
+	d.charts = append(d.charts, chartPart{relID: relID, space: space})
+	d.WordDocument.ContentTypes.AddOverride("/"+chartFile(len(d.charts)), gooxml.ChartContentType)
+
+	c := chart.MakeChart(space)
+	c.Properties().SetSolidFill(color.White)
+
+	t := c.AddTitle()
+	t.SetText(title)
+	t.RunProperties().SetSize(12 * measurement.Point)
+	t.RunProperties().SetFont("Aptos")
+	c.AddLegend().SetPosition(crt.ST_LegendPosB)
+
+	run := d.WordDocument.AddParagraph().AddRun().X()
+	run.EG_RunInnerContent = append(run.EG_RunInnerContent,
+		&wml.EG_RunInnerContent{Drawing: chartDrawing(relID, len(d.charts))})
+	return c
+}
+
+// chartDrawing returns the inline drawing that shows the chart with the
+// relationship ID
+func chartDrawing(relID string, n int) *wml.CT_Drawing {
+	inline := wml.NewWdInline()
+	inline.DistTAttr = gooxml.Uint32(0)
+	inline.DistLAttr = gooxml.Uint32(0)
+	inline.DistBAttr = gooxml.Uint32(0)
+	inline.DistRAttr = gooxml.Uint32(0)
+	inline.Extent.CxAttr = int64(chartWidth / measurement.EMU)
+	inline.Extent.CyAttr = int64(chartHeight / measurement.EMU)
+
+	// Word rejects drawing IDs that do not fit an int32
+	inline.DocPr.IdAttr = 0x7FFFFFFF & rand.Uint32()
+	inline.DocPr.NameAttr = fmt.Sprintf("Chart %d", n)
+	inline.CNvGraphicFramePr = dml.NewCT_NonVisualGraphicFrameProperties()
+
+	ref := crt.NewChart()
+	ref.IdAttr = relID
+	inline.Graphic = dml.NewGraphic()
+	inline.Graphic.GraphicData = dml.NewCT_GraphicalObjectData()
+	inline.Graphic.GraphicData.UriAttr = chartURI
+	inline.Graphic.GraphicData.Any = []gooxml.Any{ref}
+
+	drawing := wml.NewCT_Drawing()
+	drawing.Inline = append(drawing.Inline, inline)
+	return drawing
+}
+
+// chartFile returns the name of the nth chart part in the package
+func chartFile(n int) string {
+	return fmt.Sprintf("word/charts/chart%d.xml", n)
+}
+
+// save writes the document package. gooxml cannot relate chart parts to the
+// document, so documents with charts are rewritten to add them.
+func (d *Doc) save(w io.Writer) error {
+	if len(d.charts) == 0 {
+		return d.WordDocument.Save(w)
+	}
+
+	var buf bytes.Buffer
+	if err := d.WordDocument.Save(&buf); err != nil {
+		return err
+	}
+	return d.addChartParts(buf.Bytes(), w)
+}
+
+// addChartParts copies the package to w, adding the chart parts and their
+// relationships to the document
+func (d *Doc) addChartParts(pkg []byte, w io.Writer) error {
+	r, err := zip.NewReader(bytes.NewReader(pkg), int64(len(pkg)))
+	if err != nil {
+		return fmt.Errorf("failed to read document package: %w", err)
+	}
+
+	z := zip.NewWriter(w)
+	for _, f := range r.File {
+		if f.Name == documentRels {
+			err = d.writeDocumentRels(z, f)
+		} else {
+			err = z.Copy(f)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to copy %s: %w", f.Name, err)
+		}
+	}
+
+	for i, c := range d.charts {
+		if err := zippkg.MarshalXML(z, chartFile(i+1), c.space); err != nil {
+			return fmt.Errorf("failed to write chart: %w", err)
+		}
+	}
+	return z.Close()
+}
+
+// writeDocumentRels writes the document relationships with those of the
+// charts added
+func (d *Doc) writeDocumentRels(z *zip.Writer, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	rels, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	var charts strings.Builder
+	for i, c := range d.charts {
+		fmt.Fprintf(&charts, `<Relationship Id="%s" Type="%s" Target="charts/chart%d.xml"></Relationship>`,
+			c.relID, gooxml.ChartType, i+1)
+	}
+	const end = "</Relationships>"
+	if !bytes.Contains(rels, []byte(end)) {
+		return fmt.Errorf("unexpected relationships in %s", f.Name)
+	}
+	rels = bytes.Replace(rels, []byte(end), []byte(charts.String()+end), 1)
+
+	header := f.FileHeader
+	out, err := z.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(rels)
+	return err
+}
@@ -3,6 +3,7 @@ package word
 import (
 	"bytes"
 	"log"
+	"os"
 
 	"github.com/carmel/gooxml/document"
 )
@@ -10,6 +11,9 @@ import (
 // Table represents a Word document table wrapper
 type Doc struct {
 	WordDocument document.Document
+
+	// charts are written into the package on save
+	charts []chartPart
 }
 
 // AddHeading adds a heading to the document
@@ -20,6 +24,11 @@ func (d *Doc) AddHeading(headingLevel int, headingText string) {
 	heading1.AddRun().AddText(headingText)
 }
 
+// AddParagraph adds a paragraph of plain text to the document
+func (d *Doc) AddParagraph(text string) {
+	d.WordDocument.AddParagraph().AddRun().AddText(text)
+}
+
 // NewDoc creates a new document with default settings
 func NewDocument() *Doc {
 	wordDocument := document.New()
@@ -29,7 +38,7 @@ func NewDocument() *Doc {
 // SaveDocument saves the Word document to the specified output file
 func (d *Doc) SaveDocumentToFile(outputFile *string) error {
 	// Save the document
-	err := d.WordDocument.SaveToFile(*outputFile)
+	err := d.saveToFile(*outputFile)
 	if err != nil {
 		log.Fatalf("Failed to save document: %v", err)
 		return err
@@ -40,10 +49,23 @@ func (d *Doc) SaveDocumentToFile(outputFile *string) error {
 // SaveDocument saves the Word document to the specified output file
 func (d *Doc) SaveDocument(buf bytes.Buffer) error {
 	// Save the document
-	err := d.WordDocument.Save(&buf)
+	err := d.save(&buf)
 	if err != nil {
 		log.Fatalf("Failed to save document: %v", err)
 		return err
 	}
 	return nil
 }
+
+// saveToFile writes the document package to the file
+func (d *Doc) saveToFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := d.save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -10,6 +10,9 @@ import (
 // Table represents a Word document table wrapper
 type Table struct {
 	table document.Table
+	// leftAligned holds the indexes of the data columns aligned left, the
+	// others are centered
+	leftAligned map[int]bool
 }
 
 // NewTable creates a new table in the document with default settings
@@ -21,7 +24,16 @@ func NewTable(doc *document.Document) *Table {
 	borders := table.Properties().Borders()
 	borders.SetAll(wml.ST_BorderSingle, color.Black, measurement.Point)
 
-	return &Table{table: table}
+	return &Table{table: table, leftAligned: map[int]bool{2: true, 3: true}}
+}
+
+// SetLeftAligned sets which data columns are aligned left, by index. By
+// default these are columns 2 and 3 (summary and epic).
+func (t *Table) SetLeftAligned(columns ...int) {
+	t.leftAligned = make(map[int]bool, len(columns))
+	for _, c := range columns {
+		t.leftAligned[c] = true
+	}
 }
 
 // AddHeaderRow creates a header row with the specified cell values
@@ -53,7 +65,7 @@ func (t *Table) AddDataRow(data []string) {
 		cell := dataRow.AddCell()
 		setCellMargins(cell)
 		para := cell.AddParagraph()
-		if i != 2 && i != 3 {
+		if !t.leftAligned[i] {
 			para.Properties().SetAlignment(wml.ST_JcCenter)
 		}
 		run := para.AddRun()